@@ -1,89 +1,309 @@
 package kli
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 )
 
 type App struct {
-	root Command
-	seen []Command
+	root     Command
+	seen     []Command
+	grace    time.Duration
+	graceSet bool
+
+	completion bool
+	specDump   bool
+	prefix     bool
+	strict     bool
+	envPrefix  string
+	configFile string
+	configFlag bool
+	explain    bool
 }
 
 func (a *App) SetRoot(root Command) {
 	a.root = root
 }
 
+// Root returns the root command of the app
+func (a *App) Root() Command {
+	return a.root
+}
+
 // Run runs the app with the given argument list
 // usually it's the os.Args[1:]
+// Run exits the program with the code returned by Execute.
+// The first SIGINT or SIGTERM cancels the command's context, see SetGracePeriod,
+// the program then exits with 128 + the signal number (130 for Ctrl-C)
 func (a *App) Run(ctx *Context) {
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, terminationSignals...)
+	code, err := a.executeWithSignals(ctx, sigs)
+	signal.Stop(sigs)
+	if err != nil {
+		log.Println(err.Error())
+	}
+	os.Exit(code)
+}
+
+// Execute walks the command tree with the given argument list,
+// parses the flags of every command on the way and executes the last one.
+// It returns the exit code and the error, if any, instead of exiting
+// so the app can be embedded or tested in-process.
+// The context is handed to every command on the path, see Command::Context
+func (a *App) Execute(ctx *Context) (int, Error) {
+	if ctx.Context == nil {
+		ctx.SetContext(context.Background())
+	}
+	if err := a.setup(); err != nil {
+		return GeneralError, ErrorWrap(err, "could not setup the app", GeneralError)
+	}
+	// forget the state of a previous execution
+	a.seen = nil
+	reset(a.root)
+	if a.completion && len(ctx.Args()) > 0 && ctx.Args()[0] == CompleteCommandName {
+		if err := a.Complete(os.Stdout, ctx.Args()[1:]); err != nil {
+			return GeneralError, ErrorWrap(err, "could not complete", GeneralError)
+		}
+		return OK, nil
+	}
+	if a.specDump && wantsSpec(ctx.Args()) {
+		if err := a.WriteSpec(os.Stdout); err != nil {
+			return GeneralError, ErrorWrap(err, "could not write the spec", GeneralError)
+		}
+		return OK, nil
+	}
+	explain := a.explain && hasFlag(ctx.Args(), ExplainFlag)
+	if explain {
+		ctx.SetArgs(withoutFlag(ctx.Args(), ExplainFlag))
+	}
+	if err := a.loadConfig(ctx.Args()); err != nil {
+		return MisuseError, ErrorWrap(err, "could not load the configuration", MisuseError)
+	}
 
 	if len(ctx.Args()) == 0 && !a.root.IsExecutable() {
-		// only one argument, call the excute the root fnc right away
+		// no arguments and nothing to execute, print the defaults
 		fmt.Println("no arguments, printing default")
 		a.root.PrintDefaults()
-		os.Exit(0)
-		return
+		return OK, nil
 	}
 	// os.Arg[0] is the path
 	// os.Arg[1] is the command (the root) -- we don't care for it's name
 	// since we want to be able to rename the command without changing
 	// the name of the root command
 	// always parse to root element flag since they are the globals
-	e := a.root.Parse(ctx.Args())
-	if e != nil {
-		log.Printf("could not parse arguments: %s", e.Error())
-		os.Exit(1)
-	}
-	args := a.root.Args()
 	a.seen = []Command{a.root}
-
-	if len(args) >= 1 {
-		a.compute(a.root.Children(), args)
+	err := parse(a.root, ctx.Args())
+	if err == nil {
+		err = a.compute(a.root, a.root.Args())
+	}
+	if err == errHelp {
+		return OK, nil
 	}
+	if err != nil {
+		return err.Code(), err
+	}
+	for _, c := range a.seen {
+		c.SetContext(ctx)
+	}
+	if explain {
+		if err := a.ExplainFlags(os.Stdout); err != nil {
+			return GeneralError, ErrorWrap(err, "could not explain the flags", GeneralError)
+		}
+		return OK, nil
+	}
+
 	//args of the first command are the global
 	first := a.seen[0]
 	// the last command is the one to execute
 	last := a.seen[len(a.seen)-1]
 	if !last.IsExecutable() {
-		log.Printf("command %s does not have an executing method", last.Name())
 		last.PrintDefaults()
-		os.Exit(1)
+		return GeneralError, NewErrorf(GeneralError, "command %s does not have an executing method", last.Name())
+	}
+
+	if perr := last.ParseArgs(); perr != nil {
+		return MisuseError, ErrorWrap(perr, fmt.Sprintf("invalid arguments for %s", last.Name()), MisuseError)
 	}
 
-	err := last.Execute(last, first.GetKFlag())
+	err = last.Execute(last, first.GetKFlag())
 	if err != nil {
-		log.Println(err.Error())
-		os.Exit(err.Code())
+		return err.Code(), err
 	}
 
-	os.Exit(0)
+	return OK, nil
 }
 
-func (a *App) compute(cmds []Command, args []string) {
-	if len(args) < 1 {
-		return
+// compute looks for a child of cmd in the arguments, parses it's flags
+// and continues with it's own children. The arguments that are not
+// a child name are skipped, unless the routing is strict, see SetStrictRouting.
+// When no child is found, a skipped word close to a child name
+// returns an unknown command error with the suggestions
+func (a *App) compute(cmd Command, args []string) Error {
+	var skipped []string
+	for len(args) > 0 {
+		// pop-front
+		// todo maybe it would be more performance to pop
+		var arg string
+		arg, args = args[0], args[1:]
+
+		c, err := a.match(cmd.Children(), arg)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			skipped = append(skipped, arg)
+			if a.strict {
+				// the argument is a positional argument of cmd
+				break
+			}
+			continue
+		}
+
+		//add to seen
+		a.seen = append(a.seen, c)
+		//parse arguments
+		if err := parse(c, args); err != nil {
+			return err
+		}
+		return a.compute(c, c.Args())
 	}
 
-	// pop-front
-	// todo maybe it would be more performance to pop
-	arg, args := args[0], args[1:]
+	if len(skipped) == 0 || len(cmd.Children()) == 0 {
+		return nil
+	}
+	return unknownCommand(cmd, skipped[0])
+}
 
+// unknownCommand returns an error if the word is not a child of cmd
+// but is close to one, or if cmd can't be executed with the word as argument.
+// the word is a valid argument if cmd is executable and declares positional arguments
+func unknownCommand(cmd Command, word string) Error {
+	if cmd.IsExecutable() && len(cmd.GetPositional()) > 0 {
+		return nil
+	}
+
+	var names []string
+	for _, c := range cmd.Children() {
+		names = append(names, c.Name())
+		names = append(names, c.GetAliases()...)
+	}
+
+	suggestions := suggest(word, names, false)
+	if len(suggestions) == 0 && cmd.IsExecutable() {
+		return nil
+	}
+	return NewErrorf(NotFount, "unknown command %q for %s%s", word, cmd.Name(), didYouMean(suggestions, ""))
+}
+
+// errHelp is returned by parse when the help flag was given
+// the usage is printed by the command, the execution stops without error
+var errHelp = &KError{e: flag.ErrHelp, c: OK}
+
+// parse parses the command flags
+// as per the command error handling the error is returned, it exits or it panics.
+// errors that are not an Error have the MisuseError code
+func parse(cmd Command, args []string) Error {
+	err := cmd.Parse(args)
+	if err == nil {
+		return nil
+	}
+	if err == flag.ErrHelp {
+		return errHelp
+	}
+	if kerr, ok := err.(Error); ok {
+		return kerr
+	}
+	return ErrorWrap(err, fmt.Sprintf("could not parse the flags of %s", cmd.Name()), MisuseError)
+}
+
+// SetStrictRouting makes the first argument following the command flags
+// either a child command or the first positional argument of the command,
+// in which case it is the command to execute. By default the arguments
+// are scanned until a child command is found
+func (a *App) SetStrictRouting(enabled bool) {
+	a.strict = enabled
+}
+
+// SetPrefixMatching makes an unambiguous prefix of a command name,
+// or alias, match the command (ex: sta for status)
+func (a *App) SetPrefixMatching(enabled bool) {
+	a.prefix = enabled
+}
+
+// match returns the command named, or aliased, arg. nil if none matches
+// with prefix matching, an error lists the candidates when the prefix is ambiguous
+func (a *App) match(cmds []Command, arg string) (Command, Error) {
+	for _, c := range cmds {
+		if isNamed(c, arg) {
+			return c, nil
+		}
+	}
+	if !a.prefix || arg == "" {
+		return nil, nil
+	}
+
+	var found []Command
+	var names []string
 	for _, c := range cmds {
-		if arg == c.Name() {
-			//parse arguments
-			err := c.Parse(args)
-			if err != nil {
-				log.Printf("could not parse argument: %s", err.Error())
+		for _, name := range append([]string{c.Name()}, c.GetAliases()...) {
+			if strings.HasPrefix(name, arg) {
+				found = append(found, c)
+				names = append(names, name)
+				break
 			}
-			args = c.Args()
-			//add to seen
-			a.seen = append(a.seen, c)
-			a.compute(c.Children(), args)
-			return
 		}
 	}
 
-	a.compute(cmds, args)
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return found[0], nil
+	}
+	return nil, NewErrorf(MisuseError, "ambiguous command %q, it could be: %s", arg, strings.Join(names, ", "))
+}
+
+// isNamed returns true if the command name, or one of it's aliases, is name
+func isNamed(cmd Command, name string) bool {
+	if cmd.Name() == name {
+		return true
+	}
+	for _, alias := range cmd.GetAliases() {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reset resets the command and all of it's descendants
+func reset(cmd Command) {
+	cmd.Reset()
+	for _, child := range cmd.Children() {
+		reset(child)
+	}
+}
+
+// setup adds the built-in commands to the root command
+// and configures the command tree
+func (a *App) setup() error {
+	if a.completion && childNamed(a.root, CompletionCommandName) == nil {
+		if err := a.root.SetChildren(NewCompletionCommand(a.root)); err != nil {
+			return err
+		}
+	}
+	if a.envPrefix != "" {
+		setEnvPrefix(a.root, a.envPrefix)
+	}
+	if a.configFlag && a.root.Lookup(ConfigFlag) == nil {
+		a.root.String(ConfigFlag, "", "the configuration file")
+	}
+	return nil
 }
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"github.com/SamuelTissot/kli"
 	"github.com/SamuelTissot/kli/ktest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestApp_ParseSubCommands_withGlobalFlags(t *testing.T) {
@@ -130,8 +134,8 @@ func TestApp_noArgs(t *testing.T) {
 	}
 
 	mustFind := []string{
-		"ROOT - all of your root needs",
-		"SUB - a sub command, yeah!",
+		"ROOT : all of your root needs",
+		"SUB : a sub command, yeah!",
 	}
 
 	got := string(kt.Out)
@@ -200,3 +204,408 @@ func TestApp_Help(t *testing.T) {
 		}
 	}
 }
+
+func TestApp_Execute(t *testing.T) {
+	root := kli.NewCommand("root", flag.ContinueOnError)
+	root.String("foo", "", "the echoed string")
+
+	var got string
+	sub := kli.NewCommand("sub", flag.ContinueOnError)
+	sub.Do(func(_ kli.Command, globals kli.KFlag) kli.Error {
+		got, _ = globals.StringFlag("foo")
+		return nil
+	})
+
+	fail := kli.NewCommand("fail", flag.ContinueOnError)
+	fail.Do(func(_ kli.Command, _ kli.KFlag) kli.Error {
+		return kli.NewError("it failed", kli.MisuseError)
+	})
+
+	err := root.SetChildren(sub, fail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	code, kerr := app.Execute(kli.NewContext().SetArgs([]string{"-foo", "bar", "sub"}))
+	if code != kli.OK || kerr != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, kerr)
+	}
+	if got != "bar" {
+		t.Errorf("expected the global flag foo to be \"bar\", got \"%s\"", got)
+	}
+
+	code, kerr = app.Execute(kli.NewContext().SetArgs([]string{"fail"}))
+	if code != kli.MisuseError {
+		t.Errorf("expected code %d, got %d", kli.MisuseError, code)
+	}
+	if kerr == nil || kerr.Error() != "it failed" {
+		t.Errorf("expected the error \"it failed\", got %v", kerr)
+	}
+
+	code, kerr = app.Execute(kli.NewContext().SetArgs([]string{"-foo", "bar"}))
+	if code != kli.GeneralError || kerr == nil {
+		t.Errorf("expected code %d with an error for a non executable command, got %d and %v", kli.GeneralError, code, kerr)
+	}
+}
+
+func TestApp_Execute_context(t *testing.T) {
+	type key string
+
+	root := kli.NewCommand("root", flag.ContinueOnError)
+	root.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+		ctx := cmd.Context()
+		if v, _ := ctx.Value(key("who")).(string); v != "cow" {
+			return kli.NewErrorf(kli.GeneralError, "expected the value \"cow\", got \"%s\"", v)
+		}
+		select {
+		case <-ctx.Done():
+			return kli.ErrorWrap(ctx.Err(), "cancelled", kli.UserTermination)
+		case <-time.After(time.Second):
+			return kli.NewError("the context was not cancelled", kli.GeneralError)
+		}
+	})
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	ctx := kli.NewContext().WithValue(key("who"), "cow")
+	cancel := ctx.WithTimeout(time.Millisecond)
+	defer cancel()
+
+	code, err := app.Execute(ctx)
+	if code != kli.UserTermination {
+		t.Errorf("expected code %d, got %d: %v", kli.UserTermination, code, err)
+	}
+}
+
+func TestApp_Execute_twice(t *testing.T) {
+	path := writeConfig(t, "cow.ini", "what = meuh\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	var what, name string
+	var tags []string
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.String("what", "moo", "what the cow will say")
+	root.String("name", "bessie", "the name of the cow")
+	root.StringSlice("tag", []string{"latest"}, "the tags")
+	root.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+		what, _ = cmd.StringFlag("what")
+		name, _ = cmd.StringFlag("name")
+		tags, _ = cmd.StringSliceFlag("tag")
+		return nil
+	})
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.EnableConfigFlag()
+
+	runs := []struct {
+		args []string
+		what string
+		name string
+		tags []string
+	}{
+		{[]string{"-config", path, "-name", "daisy", "-tag", "a"}, "meuh", "daisy", []string{"a"}},
+		{[]string{"-tag", "b"}, "moo", "bessie", []string{"b"}},
+		{[]string{}, "moo", "bessie", []string{"latest"}},
+	}
+	for _, run := range runs {
+		code, err := app.Execute(kli.NewContext().SetArgs(run.args))
+		if code != kli.OK || err != nil {
+			t.Fatalf("%v: expected code %d and no error, got %d and %v", run.args, kli.OK, code, err)
+		}
+		if what != run.what || name != run.name || !reflect.DeepEqual(tags, run.tags) {
+			t.Errorf("%v: expected %s, %s and %v, got %s, %s and %v", run.args, run.what, run.name, run.tags, what, name, tags)
+		}
+		if root.Changed("name") != (run.name != "bessie") {
+			t.Errorf("%v: expected name to be changed only when set, got %v", run.args, root.Changed("name"))
+		}
+	}
+}
+
+func TestApp_Run_interrupted(t *testing.T) {
+
+	kt := ktest.NewKT()
+	kt.Exec(t, func(t *testing.T) {
+		root := kli.NewCommand("root", flag.ExitOnError)
+		root.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				return kli.ErrorWrap(err, "could not find the process", kli.GeneralError)
+			}
+			if err := p.Signal(os.Interrupt); err != nil {
+				return kli.ErrorWrap(err, "could not send the signal", kli.GeneralError)
+			}
+
+			<-cmd.Context().Done()
+			fmt.Println("cleaning up")
+			return nil
+		})
+
+		app := &kli.App{}
+		app.SetRoot(root)
+		app.SetGracePeriod(time.Second)
+
+		app.Run(kli.NewContext().SetArgs([]string{}))
+	})
+
+	exitErr, ok := kt.Err.(*exec.ExitError)
+	if !ok {
+		t.Fatalf("expected an exit error, got %v", kt.Err)
+	}
+	if exitErr.ExitCode() != kli.UserTermination {
+		t.Errorf("expected exit code %d, got %d", kli.UserTermination, exitErr.ExitCode())
+	}
+	if !strings.Contains(string(kt.Out), "cleaning up") {
+		t.Errorf("the command did not clean up, got \"%s\"", kt.Out)
+	}
+}
+
+func TestApp_Execute_aliasesAndPrefix(t *testing.T) {
+	var ran string
+	newCmd := func(name string, aliases ...string) kli.Command {
+		cmd := kli.NewCommand(name, flag.ContinueOnError)
+		cmd.Aliases(aliases...)
+		cmd.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+			ran = cmd.Name()
+			return nil
+		})
+		return cmd
+	}
+
+	root := kli.NewCommand("root", flag.ContinueOnError)
+	err := root.SetChildren(newCmd("remove", "rm"), newCmd("status"), newCmd("start"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.SetPrefixMatching(true)
+
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"rm", "remove"},
+		{"remove", "remove"},
+		{"re", "remove"},
+		{"stat", "status"},
+		{"star", "start"},
+	}
+	for _, tt := range tests {
+		ran = ""
+		code, err := app.Execute(kli.NewContext().SetArgs([]string{tt.arg}))
+		if code != kli.OK || err != nil {
+			t.Errorf("%s: expected code %d and no error, got %d and %v", tt.arg, kli.OK, code, err)
+		}
+		if ran != tt.want {
+			t.Errorf("%s: expected %s to run, got %s", tt.arg, tt.want, ran)
+		}
+	}
+
+	code, kerr := app.Execute(kli.NewContext().SetArgs([]string{"sta"}))
+	if code != kli.MisuseError || kerr == nil {
+		t.Fatalf("expected code %d with an error for an ambiguous prefix, got %d and %v", kli.MisuseError, code, kerr)
+	}
+	if !strings.Contains(kerr.Error(), "status, start") {
+		t.Errorf("expected the candidates in the error, got %s", kerr.Error())
+	}
+}
+
+func TestApp_Execute_suggestions(t *testing.T) {
+	root := kli.NewCommand("root", flag.ContinueOnError)
+	root.String("foo", "", "the echoed string")
+	root.Do(func(kli.Command, kli.KFlag) kli.Error {
+		return nil
+	})
+
+	status := kli.NewCommand("status", flag.ContinueOnError)
+	status.Do(func(kli.Command, kli.KFlag) kli.Error {
+		return nil
+	})
+	err := root.SetChildren(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"sttaus"}, `unknown command "sttaus" for root, did you mean "status"?`},
+		{[]string{"-fooo", "bar"}, `unknown flag "-fooo" for root, did you mean "-foo"?`},
+	}
+	for _, tt := range tests {
+		code, err := app.Execute(kli.NewContext().SetArgs(tt.args))
+		if code != kli.NotFount || err == nil {
+			t.Fatalf("%v: expected code %d with an error, got %d and %v", tt.args, kli.NotFount, code, err)
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%v: expected the error %q, got %q", tt.args, tt.want, err.Error())
+		}
+	}
+
+	// a word far from any command name is a positional argument of the root
+	code, kerr := app.Execute(kli.NewContext().SetArgs([]string{"elephant"}))
+	if code != kli.OK || kerr != nil {
+		t.Errorf("expected code %d and no error, got %d and %v", kli.OK, code, kerr)
+	}
+}
+
+func TestApp_Execute_suggestionsPositional(t *testing.T) {
+	var got []string
+	root := kli.NewCommand("tool", flag.ContinueOnError)
+	root.Positional(kli.Arg{Name: "files", Variadic: true})
+	root.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+		got, _ = cmd.StringArgs("files")
+		return nil
+	})
+	for _, name := range []string{"add", "rm"} {
+		child := kli.NewCommand(name, flag.ContinueOnError)
+		child.Do(func(kli.Command, kli.KFlag) kli.Error {
+			return nil
+		})
+		if err := root.SetChildren(child); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	// the words close to a child name are the declared positional arguments of the root
+	for _, word := range []string{"ab", "ad", "ls", "addd"} {
+		code, err := app.Execute(kli.NewContext().SetArgs([]string{word}))
+		if code != kli.OK || err != nil {
+			t.Errorf("%s: expected code %d and no error, got %d and %v", word, kli.OK, code, err)
+		}
+		if len(got) != 1 || got[0] != word {
+			t.Errorf("%s: expected the positional argument, got %v", word, got)
+		}
+	}
+}
+
+func TestApp_Execute_suggestionsShortWords(t *testing.T) {
+	root := kli.NewCommand("tool", flag.ContinueOnError)
+	for _, name := range []string{"add", "rm"} {
+		child := kli.NewCommand(name, flag.ContinueOnError)
+		child.Do(func(kli.Command, kli.KFlag) kli.Error {
+			return nil
+		})
+		if err := root.SetChildren(child); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	// the root is not executable, the word is an unknown command without suggestion
+	_, err := app.Execute(kli.NewContext().SetArgs([]string{"ls"}))
+	if err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("expected an unknown command error without suggestion, got %v", err)
+	}
+}
+
+func TestApp_Execute_strictRouting(t *testing.T) {
+	var ran string
+	var args []string
+	newCmd := func(name string) *kli.CMD {
+		cmd := kli.NewCommand(name, flag.ContinueOnError)
+		cmd.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+			ran = cmd.Name()
+			args = cmd.Args()
+			return nil
+		})
+		return cmd
+	}
+
+	root := newCmd("root")
+	foo := newCmd("foo")
+	err := root.SetChildren(foo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = foo.SetChildren(newCmd("sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	tests := []struct {
+		strict   bool
+		args     []string
+		wantCmd  string
+		wantArgs []string
+	}{
+		{false, []string{"bar", "baz", "foo", "sub"}, "sub", []string{}},
+		{true, []string{"bar", "baz", "foo", "sub"}, "root", []string{"bar", "baz", "foo", "sub"}},
+		{true, []string{"foo", "bar", "sub"}, "foo", []string{"bar", "sub"}},
+		{true, []string{"foo", "sub", "bar"}, "sub", []string{"bar"}},
+	}
+	for _, tt := range tests {
+		app.SetStrictRouting(tt.strict)
+		code, err := app.Execute(kli.NewContext().SetArgs(tt.args))
+		if code != kli.OK || err != nil {
+			t.Fatalf("%v: expected code %d and no error, got %d and %v", tt.args, kli.OK, code, err)
+		}
+		if ran != tt.wantCmd || strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+			t.Errorf("%v: expected %s to run with %v, got %s with %v", tt.args, tt.wantCmd, tt.wantArgs, ran, args)
+		}
+	}
+}
+
+func TestApp_Execute_parseErrors(t *testing.T) {
+	newApp := func(handling flag.ErrorHandling, ran *bool) *kli.App {
+		root := kli.NewCommand("root", flag.ContinueOnError)
+		sub := kli.NewCommand("sub", handling)
+		sub.Int("repeat", 1, "how many time it repeats the word")
+		sub.Do(func(kli.Command, kli.KFlag) kli.Error {
+			*ran = true
+			return nil
+		})
+		err := root.SetChildren(sub)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		app := &kli.App{}
+		app.SetRoot(root)
+		return app
+	}
+
+	var ran bool
+	app := newApp(flag.ContinueOnError, &ran)
+
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"sub", "-repeat", "twice"}))
+	if code != kli.MisuseError || err == nil {
+		t.Errorf("expected code %d with an error, got %d and %v", kli.MisuseError, code, err)
+	}
+	if ran {
+		t.Error("the command should not run with invalid flags")
+	}
+
+	code, err = app.Execute(kli.NewContext().SetArgs([]string{"sub", "-h"}))
+	if code != kli.OK || err != nil {
+		t.Errorf("expected code %d and no error for the help flag, got %d and %v", kli.OK, code, err)
+	}
+	if ran {
+		t.Error("the command should not run when the help is asked")
+	}
+
+	app = newApp(flag.PanicOnError, &ran)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected the command to panic on a parse error")
+		}
+	}()
+	_, _ = app.Execute(kli.NewContext().SetArgs([]string{"sub", "-repeat", "twice"}))
+}
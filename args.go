@@ -0,0 +1,243 @@
+package kli
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArgType is the type of a positional argument
+type ArgType int
+
+const (
+	ArgString ArgType = iota
+	ArgInt
+	ArgFloat64
+	ArgDuration
+	// ArgPath is a file path, it is cleaned with filepath.Clean
+	ArgPath
+)
+
+func (t ArgType) String() string {
+	switch t {
+	case ArgInt:
+		return "int"
+	case ArgFloat64:
+		return "float64"
+	case ArgDuration:
+		return "duration"
+	case ArgPath:
+		return "path"
+	}
+	return "string"
+}
+
+// Arg declares a positional argument
+// the optional arguments follow the required ones
+// and only the last argument can be variadic
+type Arg struct {
+	Name     string
+	Usage    string
+	Type     ArgType
+	Optional bool
+	// Variadic takes all the remaining arguments
+	// at least one if it's not optional
+	Variadic bool
+}
+
+// String returns the argument as shown in the usage line
+// <name> when required, [name] when optional, followed by ... when variadic
+func (a Arg) String() string {
+	name := a.Name
+	if a.Variadic {
+		name += "..."
+	}
+	if a.Optional {
+		return "[" + name + "]"
+	}
+	return "<" + name + ">"
+}
+
+// parse converts the value as per the argument type
+func (a Arg) parse(value string) (interface{}, error) {
+	switch a.Type {
+	case ArgInt:
+		return strconv.Atoi(value)
+	case ArgFloat64:
+		return strconv.ParseFloat(value, 64)
+	case ArgDuration:
+		return time.ParseDuration(value)
+	case ArgPath:
+		return filepath.Clean(value), nil
+	}
+	return value, nil
+}
+
+// Positional declares the positional arguments of the command
+// they are validated by ParseArgs before the command is executed.
+// Positional panics if a required argument follows an optional one
+// or if an argument follows a variadic one
+func (c *CMD) Positional(args ...Arg) {
+	for _, arg := range args {
+		if n := len(c.positional); n > 0 {
+			last := c.positional[n-1]
+			if last.Variadic {
+				panic(fmt.Sprintf("%s: argument %s follows the variadic argument %s", c.Name(), arg, last))
+			}
+			if last.Optional && !arg.Optional {
+				panic(fmt.Sprintf("%s: required argument %s follows the optional argument %s", c.Name(), arg, last))
+			}
+		}
+		c.positional = append(c.positional, arg)
+	}
+}
+
+// GetPositional returns the declared positional arguments
+func (c *CMD) GetPositional() []Arg {
+	return c.positional
+}
+
+// Synopsis returns the usage line of the command
+// ex: cow say [flags] <what> [repeat]
+func (c *CMD) Synopsis() string {
+	usage := []string{strings.Join(CommandPath(c), " ")}
+	hasFlags := false
+	c.FlagSet.VisitAll(func(*flag.Flag) {
+		hasFlags = true
+	})
+	if hasFlags {
+		usage = append(usage, "[flags]")
+	}
+	if len(c.children) > 0 {
+		usage = append(usage, "[command]")
+	}
+	for _, arg := range c.positional {
+		usage = append(usage, arg.String())
+	}
+	return strings.Join(usage, " ")
+}
+
+// ParseArgs validates the positional arguments, Command::Args, against their declaration
+// and converts them to their type. Nothing is validated when no argument was declared
+func (c *CMD) ParseArgs() error {
+	if len(c.positional) == 0 {
+		return nil
+	}
+
+	args := c.Args()
+	c.argValues = make(map[string]interface{})
+	for i, spec := range c.positional {
+		if i >= len(args) {
+			if !spec.Optional {
+				return fmt.Errorf("missing argument %s, usage: %s", spec, c.Synopsis())
+			}
+			continue
+		}
+
+		values := args[i : i+1]
+		if spec.Variadic {
+			values = args[i:]
+		}
+		var parsed []interface{}
+		for _, v := range values {
+			p, err := spec.parse(v)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for argument %s: %s", v, spec, err.Error())
+			}
+			parsed = append(parsed, p)
+		}
+		if spec.Variadic {
+			c.argValues[spec.Name] = parsed
+			return nil
+		}
+		c.argValues[spec.Name] = parsed[0]
+	}
+
+	if len(args) > len(c.positional) {
+		return fmt.Errorf("unexpected argument %q, usage: %s", args[len(c.positional)], c.Synopsis())
+	}
+	return nil
+}
+
+// argValue returns the parsed value of the positional argument "name"
+func (c *CMD) argValue(name string) (interface{}, bool) {
+	v, ok := c.argValues[name]
+	return v, ok
+}
+
+// variadicValues returns the parsed values of the variadic positional argument "name"
+func (c *CMD) variadicValues(name string) []interface{} {
+	v, _ := c.argValues[name].([]interface{})
+	return v
+}
+
+func (c *CMD) StringArg(name string) (value string, ok bool) {
+	v, _ := c.argValue(name)
+	value, ok = v.(string)
+	return
+}
+
+func (c *CMD) IntArg(name string) (value int, ok bool) {
+	v, _ := c.argValue(name)
+	value, ok = v.(int)
+	return
+}
+
+func (c *CMD) Float64Arg(name string) (value float64, ok bool) {
+	v, _ := c.argValue(name)
+	value, ok = v.(float64)
+	return
+}
+
+func (c *CMD) DurationArg(name string) (value time.Duration, ok bool) {
+	v, _ := c.argValue(name)
+	value, ok = v.(time.Duration)
+	return
+}
+
+func (c *CMD) StringArgs(name string) (values []string, ok bool) {
+	for _, v := range c.variadicValues(name) {
+		s, isString := v.(string)
+		if !isString {
+			return nil, false
+		}
+		values = append(values, s)
+	}
+	return values, values != nil
+}
+
+func (c *CMD) IntArgs(name string) (values []int, ok bool) {
+	for _, v := range c.variadicValues(name) {
+		i, isInt := v.(int)
+		if !isInt {
+			return nil, false
+		}
+		values = append(values, i)
+	}
+	return values, values != nil
+}
+
+func (c *CMD) Float64Args(name string) (values []float64, ok bool) {
+	for _, v := range c.variadicValues(name) {
+		f, isFloat := v.(float64)
+		if !isFloat {
+			return nil, false
+		}
+		values = append(values, f)
+	}
+	return values, values != nil
+}
+
+func (c *CMD) DurationArgs(name string) (values []time.Duration, ok bool) {
+	for _, v := range c.variadicValues(name) {
+		d, isDuration := v.(time.Duration)
+		if !isDuration {
+			return nil, false
+		}
+		values = append(values, d)
+	}
+	return values, values != nil
+}
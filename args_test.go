@@ -0,0 +1,103 @@
+package kli_test
+
+import (
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApp_Execute_positional(t *testing.T) {
+	var what string
+	var times int
+	var every time.Duration
+	var friends []string
+
+	say := kli.NewCommand("say", flag.ContinueOnError)
+	say.Positional(
+		kli.Arg{Name: "what", Usage: "what the cow will say"},
+		kli.Arg{Name: "times", Usage: "how many time", Type: kli.ArgInt},
+		kli.Arg{Name: "every", Usage: "the pause between words", Type: kli.ArgDuration, Optional: true},
+		kli.Arg{Name: "friends", Usage: "who listens", Optional: true, Variadic: true},
+	)
+	say.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+		what, _ = cmd.StringArg("what")
+		times, _ = cmd.IntArg("times")
+		every, _ = cmd.DurationArg("every")
+		friends, _ = cmd.StringArgs("friends")
+		return nil
+	})
+
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	err := root.SetChildren(say)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := say.Synopsis(), "cow say <what> <times> [every] [friends...]"; got != want {
+		t.Errorf("expected the synopsis %q, got %q", want, got)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	code, kerr := app.Execute(kli.NewContext().SetArgs([]string{"say", "moo", "3", "1s", "pig", "hen"}))
+	if code != kli.OK || kerr != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, kerr)
+	}
+	if what != "moo" || times != 3 || every != time.Second || strings.Join(friends, ",") != "pig,hen" {
+		t.Errorf("unexpected arguments %s %d %s %v", what, times, every, friends)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"say", "moo"}, "missing argument <times>"},
+		{[]string{"say", "moo", "three"}, `invalid value "three" for argument <times>`},
+		{[]string{"say", "moo", "3", "soon"}, `invalid value "soon" for argument [every]`},
+	}
+	for _, tt := range tests {
+		code, kerr := app.Execute(kli.NewContext().SetArgs(tt.args))
+		if code != kli.MisuseError || kerr == nil {
+			t.Fatalf("%v: expected code %d with an error, got %d and %v", tt.args, kli.MisuseError, code, kerr)
+		}
+		if !strings.Contains(kerr.Error(), tt.want) {
+			t.Errorf("%v: expected the error to contain %q, got %q", tt.args, tt.want, kerr.Error())
+		}
+	}
+}
+
+func TestCMD_ParseArgs_unexpected(t *testing.T) {
+	cmd := kli.NewCommand("say", flag.ContinueOnError)
+	cmd.Positional(kli.Arg{Name: "what"})
+
+	err := cmd.Parse([]string{"moo", "meuh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.ParseArgs()
+	if err == nil || !strings.Contains(err.Error(), `unexpected argument "meuh"`) {
+		t.Errorf("expected an unexpected argument error, got %v", err)
+	}
+}
+
+func TestCMD_Positional_invalid(t *testing.T) {
+	tests := map[string][]kli.Arg{
+		"required after optional": {{Name: "what", Optional: true}, {Name: "repeat", Type: kli.ArgInt}},
+		"after variadic":          {{Name: "words", Variadic: true}, {Name: "repeat", Optional: true}},
+	}
+	for name, args := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected Positional to panic", name)
+				}
+			}()
+			cmd := kli.NewCommand("say", flag.ContinueOnError)
+			cmd.Positional(args[0])
+			cmd.Positional(args[1:]...)
+		}()
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 )
@@ -19,13 +23,27 @@ type Command interface {
 	// Description sets the shot description (except) of the Command
 	Description(desc string)
 
+	// GetDescription returns the short description of the Command
+	GetDescription() string
+
 	// Detail sets the Command details, it's the long description
 	// like example
 	Detail(detail io.Reader)
 
+	// GetDetail returns the Command details
+	GetDetail() string
+
 	// Do sets the function to be called on execution
+	// the function can get the execution context with Command::Context
 	Do(fn func(Command, KFlag) Error)
 
+	// Context returns the execution context of the command
+	// it is never nil, it defaults to a background context
+	Context() *Context
+
+	// SetContext sets the execution context of the command
+	SetContext(ctx *Context)
+
 	// Parse parses flag definitions from the argument
 	// list, which should not include the command name.
 	// Must be called after all flags in the
@@ -33,12 +51,68 @@ type Command interface {
 	// The return value will be ErrHelp if -help or -h were set but not defined.
 	Parse([]string) error
 
+	// Reset restores the flags to their default value and forgets the state
+	// of the last execution: origins, positional arguments and configuration
+	Reset()
+
 	// Args returns the non-flag arguments.
 	Args() []string
 
+	// Positional declares the positional arguments of the command
+	Positional(args ...Arg)
+
+	// GetPositional returns the declared positional arguments
+	GetPositional() []Arg
+
+	// ParseArgs validates and converts the positional arguments
+	// as declared by Positional. It's called before the command execution
+	ParseArgs() error
+
+	// Synopsis returns the usage line of the command
+	Synopsis() string
+
+	// StringArg returns the value of the positional argument "name"
+	// of type ArgString or ArgPath
+	// ok is false if the argument was not given or of wrong type
+	StringArg(name string) (value string, ok bool)
+
+	// IntArg returns the value of the positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	IntArg(name string) (value int, ok bool)
+
+	// Float64Arg returns the value of the positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	Float64Arg(name string) (value float64, ok bool)
+
+	// DurationArg returns the value of the positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	DurationArg(name string) (value time.Duration, ok bool)
+
+	// StringArgs returns the values of the variadic positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	StringArgs(name string) (values []string, ok bool)
+
+	// IntArgs returns the values of the variadic positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	IntArgs(name string) (values []int, ok bool)
+
+	// Float64Args returns the values of the variadic positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	Float64Args(name string) (values []float64, ok bool)
+
+	// DurationArgs returns the values of the variadic positional argument "name"
+	// ok is false if the argument was not given or of wrong type
+	DurationArgs(name string) (values []time.Duration, ok bool)
+
 	// Name returns the name of the command
 	Name() string
 
+	// Aliases sets the other names the command can be called by
+	Aliases(aliases ...string)
+
+	// GetAliases returns the other names of the command
+	GetAliases() []string
+
 	// Execute calls the function that what set by Command::Do
 	// returns a Error function not found if the
 	// execute function was not set
@@ -65,10 +139,95 @@ type Command interface {
 	// PrintDefaults prints, to standard error unless configured otherwise,
 	PrintDefaults()
 
+	// VisitAll visits the command flags in lexicographical order
+	VisitAll(fn func(*flag.Flag))
+
+	// Lookup returns the Flag structure of the named flag, returning nil if none exists
+	Lookup(name string) *flag.Flag
+
+	// CompleteFlag sets the function completing the values of the flag "name"
+	CompleteFlag(name string, fn CompletionFunc)
+
+	// FlagCompletion returns the function completing the values of the flag "name"
+	// nil if none was set
+	FlagCompletion(name string) CompletionFunc
+
+	// CompleteArgs sets the function completing the positional arguments
+	CompleteArgs(fn CompletionFunc)
+
+	// ArgsCompletion returns the function completing the positional arguments
+	// nil if none was set
+	ArgsCompletion() CompletionFunc
+
+	// Env binds the flag "name" to the environment variable envVar
+	// the precedence is: command line > environment variable > default
+	Env(name, envVar string)
+
+	// SetEnvPrefix binds every flag to the environment variable
+	// named by the prefix followed by the upper snake case flag name
+	SetEnvPrefix(prefix string)
+
+	// EnvVar returns the environment variable bound to the flag "name"
+	// an empty string if none
+	EnvVar(name string) string
+
+	// SetConfig sets the flag values read from the configuration file at path
+	// the precedence is: command line > environment variable > configuration file > default
+	SetConfig(path string, values map[string][]string)
+
+	// Short sets the one letter short name of the flag "name"
+	// ex: -v for --verbose, the short boolean flags can be bundled: -xvf
+	Short(name string, short rune)
+
+	// GetShort returns the short name of the flag "name", 0 if none
+	GetShort(name string) rune
+
+	// LookupShort returns the flag with the short name, nil if none
+	LookupShort(short rune) *flag.Flag
+
+	// Negatable allows the boolean flags to be turned off with --no-name
+	Negatable(names ...string)
+
+	// IsNegatable returns true if the flag "name" can be turned off with --no-name
+	IsNegatable(name string) bool
+
+	// Required marks the flags as required
+	// Parse returns an error listing the required flags that were not set
+	Required(names ...string)
+
+	// IsRequired returns true if the flag "name" is required
+	IsRequired(name string) bool
+
+	// MutuallyExclusive allows at most one of the flags to be set
+	MutuallyExclusive(names ...string)
+
+	// RequiredTogether requires all of the flags to be set, or none of them
+	RequiredTogether(names ...string)
+
+	// OneRequired requires at least one of the flags to be set
+	OneRequired(names ...string)
+
+	// FlagGroups returns the groups declared with
+	// MutuallyExclusive, RequiredTogether and OneRequired
+	FlagGroups() []FlagGroup
+
+	// Var sets a flag holding a custom flag.Value
+	// it's value is read with KFlag::VarFlag
+	Var(value flag.Value, name string, usage string)
+
+	// Enum sets a flag of type string accepting only the choices
+	// a value outside the choices is a parse error listing them
+	Enum(name string, value string, choices []string, usage string)
+
+	// Choices returns the values accepted by the flag "name"
+	// nil if it's not an Enum flag
+	Choices(name string) []string
+
 	// Bool sets a flag of type Bool
 	Bool(name string, value bool, usage string)
 
 	// Duration sets a flag of type time.Duration (int64)
+	// an invalid duration is a parse error
 	Duration(name string, value time.Duration, usage string)
 
 	// Float64 sets a flag of type float64
@@ -88,16 +247,71 @@ type Command interface {
 
 	// Uint64 sets a flag of type Uint64
 	Uint64(name string, value uint64, usage string)
+
+	// StringSlice sets a repeatable flag of type []string
+	// ex: -tag a -tag b or -tag a,b
+	StringSlice(name string, value []string, usage string)
+
+	// IntSlice sets a repeatable flag of type []int
+	IntSlice(name string, value []int, usage string)
+
+	// DurationSlice sets a repeatable flag of type []time.Duration
+	DurationSlice(name string, value []time.Duration, usage string)
+
+	// StringMap sets a repeatable flag of type map[string]string
+	// ex: -label k=v -label k2=v2 or -label k=v,k2=v2
+	StringMap(name string, value map[string]string, usage string)
+
+	// Bytes sets a flag of type ByteSize, a human size like 10MiB
+	Bytes(name string, value ByteSize, usage string)
+
+	// Time sets a flag of type time.Time in the RFC3339 format
+	Time(name string, value time.Time, usage string)
+
+	// Date sets a flag of type time.Time in the format 2006-01-02
+	Date(name string, value time.Time, usage string)
+
+	// URL sets a flag of type *url.URL, the URL must be absolute
+	URL(name string, value *url.URL, usage string)
+
+	// IP sets a flag of type net.IP
+	IP(name string, value net.IP, usage string)
+
+	// IPNet sets a flag of type *net.IPNet in the CIDR notation
+	IPNet(name string, value *net.IPNet, usage string)
+
+	// Path sets a flag of type file path validated by the checks
+	Path(name string, value string, check PathCheck, usage string)
 }
 
 type CMD struct {
 	*flag.FlagSet
 	KFlag
 	desc     string
+	aliases  []string
 	detail   io.Reader
+	details  *string
+	ctx      *Context
+	handling flag.ErrorHandling
 	parent   Command
 	children []Command
 	fn       func(cmd Command, globals KFlag) Error
+
+	flagCompletion map[string]CompletionFunc
+	argsCompletion CompletionFunc
+
+	positional []Arg
+	argValues  map[string]interface{}
+	required   []string
+	groups     []FlagGroup
+	env        map[string]string
+	envPrefix  string
+	shorts     map[rune]string
+	negatable  []string
+	negated    []string
+	config     map[string][]string
+	configPath string
+	defaults   map[string]func()
 }
 
 // Description sets the command's description
@@ -105,32 +319,247 @@ func (c *CMD) Description(desc string) {
 	c.desc = desc
 }
 
+// GetDescription returns the command's description
+func (c *CMD) GetDescription() string {
+	return c.desc
+}
+
+// Aliases sets the other names of the command (ex: rm for remove)
+func (c *CMD) Aliases(aliases ...string) {
+	c.aliases = append(c.aliases, aliases...)
+}
+
+func (c *CMD) GetAliases() []string {
+	return c.aliases
+}
+
 func (c *CMD) Detail(detail io.Reader) {
 	c.detail = detail
+	c.details = nil
+}
+
+// GetDetail returns the command's details
+// the detail reader is read once, on the first call
+func (c *CMD) GetDetail() string {
+	if c.details == nil {
+		var b []byte
+		if c.detail != nil {
+			b, _ = ioutil.ReadAll(c.detail)
+		}
+		details := string(b)
+		c.details = &details
+	}
+	return *c.details
 }
 
+// NewCommand returns a new command
+// the error handling applies to the errors returned by Command::Parse
 func NewCommand(name string, handling flag.ErrorHandling) *CMD {
-	return &CMD{
-		FlagSet: flag.NewFlagSet(name, handling),
-		KFlag:   NewKflag(),
+	cmd := &CMD{
+		// the command applies the error handling itself, see CMD::Parse
+		FlagSet:  flag.NewFlagSet(name, flag.ContinueOnError),
+		KFlag:    NewKflag(),
+		handling: handling,
 	}
+	cmd.FlagSet.Usage = cmd.usage
+	return cmd
 }
 
 func NewSubCommand(parent Command, name string, handling flag.ErrorHandling) *CMD {
-	return &CMD{
-		FlagSet: flag.NewFlagSet(name, handling),
-		KFlag:   NewKflag(),
-		parent:  parent,
+	cmd := NewCommand(name, handling)
+	cmd.parent = parent
+	return cmd
+}
+
+// ErrorHandling returns the error handling behavior of the command
+func (c *CMD) ErrorHandling() flag.ErrorHandling {
+	return c.handling
+}
+
+// Parse parses the flags from the argument list, the short flags are expanded, see Short.
+// the values and origins of a previous Parse are reset.
+// an unknown flag returns an Error with the code NotFount suggesting the closest flags.
+// the set flags are marked as changed, the flags not set are read from their
+// environment variable, see Env, then from the configuration file, see SetConfig,
+// then the required flags and groups are validated.
+// the error is then handled as per the command flag.ErrorHandling
+func (c *CMD) Parse(args []string) error {
+	// forget the result of a previous Parse
+	c.resetFlags()
+
+	args, err := c.expandArgs(args)
+	if err == nil {
+		err = c.FlagSet.Parse(args)
+	}
+	if err != nil {
+		err = c.parseError(err)
+	} else {
+		for _, name := range c.setFlags(args) {
+			c.KFlag.SetChanged(name)
+		}
+		for _, name := range c.negated {
+			c.KFlag.SetOrigin(name, Origin{Source: SourceCommandLine, Detail: "--no-" + name})
+		}
+		err = c.parseEnv()
+		if err == nil {
+			err = c.parseConfig()
+		}
+		if err == nil {
+			err = c.validate()
+		}
 	}
+	if err == nil {
+		return nil
+	}
+
+	switch c.handling {
+	case flag.ExitOnError:
+		if err == flag.ErrHelp {
+			os.Exit(OK)
+		}
+		_, _ = fmt.Fprintln(c.FlagSet.Output(), err.Error())
+		if kerr, ok := err.(Error); ok {
+			os.Exit(kerr.Code())
+		}
+		os.Exit(MisuseError)
+	case flag.PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
+// Reset restores the flags to their default value and forgets the state
+// of the last execution: origins, positional arguments and configuration
+func (c *CMD) Reset() {
+	c.resetFlags()
+	c.argValues = nil
+	c.SetConfig("", nil)
+}
+
+// resetFlags restores the flags to their default value and forgets their origins.
+// The flags of the FlagSet are reset with their DefValue,
+// a custom flag.Value that can't parse it keeps its value
+func (c *CMD) resetFlags() {
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		if reset, ok := c.defaults[f.Name]; ok {
+			reset()
+			return
+		}
+		_ = f.Value.Set(f.DefValue)
+	})
+	c.KFlag.ResetOrigins()
+	c.negated = nil
+}
+
+// setDefault registers how the flag "name" gets back to its default value
+// for the flag types that can't parse their DefValue
+func (c *CMD) setDefault(name string, reset func()) {
+	if c.defaults == nil {
+		c.defaults = make(map[string]func())
+	}
+	c.defaults[name] = reset
+}
+
+// setFlags returns the names of the flags set by the arguments consumed by
+// the last FlagSet.Parse. FlagSet::Visit can't be used since the FlagSet
+// keeps the flags set by the previous parses
+func (c *CMD) setFlags(args []string) []string {
+	var names []string
+	consumed := args[:len(args)-len(c.FlagSet.Args())]
+	for i := 0; i < len(consumed); i++ {
+		if consumed[i] == "--" {
+			break
+		}
+		parts := strings.SplitN(strings.TrimLeft(consumed[i], "-"), "=", 2)
+		f := c.FlagSet.Lookup(parts[0])
+		if f == nil {
+			continue
+		}
+		names = append(names, f.Name)
+		if len(parts) == 1 && !isBoolFlag(f) {
+			// skip the flag value
+			i++
+		}
+	}
+	return names
+}
+
+// validate returns an Error with the MisuseError code
+// listing the required flags that were not set and the unmet flag groups
+func (c *CMD) validate() error {
+	var missing []string
+	for _, name := range c.required {
+		if !c.KFlag.Changed(name) {
+			missing = append(missing, name)
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing required flags: %s", dashed(missing)))
+	}
+	for _, g := range c.groups {
+		if err := g.validate(c.KFlag); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	c.FlagSet.Usage()
+	return NewErrorf(MisuseError, "invalid flags for %s: %s", c.Name(), strings.Join(problems, "; "))
+}
+
+// usage prints the usage message of the flag set, with the positional arguments
+func (c *CMD) usage() {
+	w := c.FlagSet.Output()
+	_, _ = fmt.Fprintf(w, "Usage of %s:\n", c.Name())
+	if len(c.positional) > 0 {
+		_, _ = fmt.Fprintf(w, "  %s\n", c.Synopsis())
+	}
+	c.FlagSet.PrintDefaults()
+	for _, arg := range c.positional {
+		_, _ = fmt.Fprintf(w, "  %s %s\n    \t%s\n", arg, arg.Type, arg.Usage)
+	}
+}
+
+// parseError returns the error of the FlagSet parsing
+// with suggestions when the flag is not defined
+func (c *CMD) parseError(err error) error {
+	const undefined = "flag provided but not defined: -"
+	if !strings.HasPrefix(err.Error(), undefined) {
+		return err
+	}
+
+	name := strings.TrimPrefix(err.Error(), undefined)
+	var names []string
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	return NewErrorf(NotFount, "unknown flag %q for %s%s", "-"+name, c.Name(), didYouMean(suggest(name, names, true), "-"))
 }
 
 func (c *CMD) Do(fn func(Command, KFlag) Error) {
 	c.fn = fn
 }
 
+// Context returns the execution context of the command
+// the context is set by the App before execution, it defaults to a background context
+func (c *CMD) Context() *Context {
+	if c.ctx == nil {
+		return NewContext()
+	}
+	return c.ctx
+}
+
+func (c *CMD) SetContext(ctx *Context) {
+	c.ctx = ctx
+}
+
 func (c *CMD) Execute(cmd Command, f KFlag) Error {
 	if !c.IsExecutable() {
-		return ErrorWrap(nil, "executable function not set", CannotExecute)
+		return NewError("executable function not set", CannotExecute)
 	}
 	return c.fn(cmd, f)
 }
@@ -147,8 +576,8 @@ func (c *CMD) GetKFlag() KFlag {
 // the method also sets the parent of the children command
 // as the current command
 func (c *CMD) SetChildren(children ...Command) error {
-	for _, c := range children {
-		err := c.SetParent(c)
+	for _, child := range children {
+		err := child.SetParent(c)
 		if err != nil {
 			return fmt.Errorf("attempting to reset the parent of a child command. %s", err.Error())
 		}
@@ -164,7 +593,7 @@ func (c *CMD) Children() []Command {
 
 // setParent
 func (c *CMD) SetParent(parent Command) error {
-	if c.parent != nil {
+	if c.parent != nil && c.parent != parent {
 		return fmt.Errorf("command %s already has the parent : %s", c.Name(), c.parent.Name())
 	}
 
@@ -177,6 +606,35 @@ func (c *CMD) Parent() Command {
 	return c.parent
 }
 
+// CommandPath returns the names of the command's ancestors
+// from the root to the command itself
+func CommandPath(cmd Command) []string {
+	var path []string
+	for ; cmd != nil; cmd = cmd.Parent() {
+		path = append([]string{cmd.Name()}, path...)
+	}
+	return path
+}
+
+func (c *CMD) CompleteFlag(name string, fn CompletionFunc) {
+	if c.flagCompletion == nil {
+		c.flagCompletion = make(map[string]CompletionFunc)
+	}
+	c.flagCompletion[name] = fn
+}
+
+func (c *CMD) FlagCompletion(name string) CompletionFunc {
+	return c.flagCompletion[name]
+}
+
+func (c *CMD) CompleteArgs(fn CompletionFunc) {
+	c.argsCompletion = fn
+}
+
+func (c *CMD) ArgsCompletion() CompletionFunc {
+	return c.argsCompletion
+}
+
 func (c *CMD) PrintDefaults() {
 	b := bytes.Buffer{}
 	w := bufio.NewWriter(&b)
@@ -192,12 +650,31 @@ func (c *CMD) PrintDefaults() {
 			_, _ = fmt.Fprintf(w, "%sarguments:\n", padding)
 			flagHeader = false
 		}
-		_, _ = fmt.Fprintf(w, "%s-%s\t%s (default: %s)\n", strings.Repeat(" ", indent*4), f.Name, f.Usage, f.DefValue)
+		required := ""
+		if c.IsRequired(f.Name) {
+			required = " (required)"
+		}
+		_, _ = fmt.Fprintf(w, "%s%s\t%s (default: %s)%s%s%s\n", strings.Repeat(" ", indent*4), FlagName(c, f.Name), f.Usage, f.DefValue, c.choicesUsage(f.Name), required, c.envUsage(f.Name))
 	})
 
-	if c.detail != nil {
+	//output the command positional arguments
+	if len(c.positional) > 0 {
+		_, _ = fmt.Fprintf(w, "%spositional arguments: %s\n", padding, c.Synopsis())
+		for _, arg := range c.positional {
+			_, _ = fmt.Fprintf(w, "%s%s\t%s (%s)\n", strings.Repeat(" ", indent*4), arg, arg.Usage, arg.Type)
+		}
+	}
+
+	if len(c.groups) > 0 {
+		_, _ = fmt.Fprintf(w, "%sflag groups:\n", padding)
+		for _, g := range c.groups {
+			_, _ = fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", indent*4), g)
+		}
+	}
+
+	if detail := c.GetDetail(); detail != "" {
 		_, _ = fmt.Fprintf(w, "%susage:\n", padding)
-		scanner := bufio.NewScanner(c.detail)
+		scanner := bufio.NewScanner(strings.NewReader(detail))
 		for scanner.Scan() {
 			_, _ = fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", indent*4), scanner.Text())
 		}
@@ -213,12 +690,33 @@ func (c *CMD) PrintDefaults() {
 	}
 }
 
+func (c *CMD) Required(names ...string) {
+	c.required = append(c.required, names...)
+}
+
+func (c *CMD) IsRequired(name string) bool {
+	for _, r := range c.required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CMD) Var(value flag.Value, name string, usage string) {
+	c.FlagSet.Var(value, name, usage)
+	c.KFlag.SetVar(name, value)
+}
+
 func (c *CMD) Bool(name string, value bool, usage string) {
 	c.KFlag.SetFlag(name, c.FlagSet.Bool(name, value, usage))
 }
 
 func (c *CMD) Duration(name string, value time.Duration, usage string) {
-	c.String(name, value.String(), usage)
+	p := new(time.Duration)
+	*p = value
+	c.FlagSet.Var((*durationValue)(p), name, usage)
+	c.KFlag.SetFlag(name, p)
 }
 
 func (c *CMD) Float64(name string, value float64, usage string) {
@@ -0,0 +1,261 @@
+package kli_test
+
+import (
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCMD_SetChildren(t *testing.T) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	say := kli.NewCommand("say", flag.ContinueOnError)
+	eat := kli.NewCommand("eat", flag.ContinueOnError)
+
+	if err := root.SetChildren(say, eat); err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range []*kli.CMD{say, eat} {
+		if child.Parent() != root {
+			t.Errorf("expected the parent of %s to be the root, got %v", child.Name(), child.Parent())
+		}
+	}
+	if root.Parent() != nil {
+		t.Errorf("expected the root not to have a parent, got %v", root.Parent())
+	}
+
+	if err := say.SetParent(root); err != nil {
+		t.Errorf("expected setting the same parent again to succeed, got %v", err)
+	}
+	if err := eat.SetParent(say); err == nil {
+		t.Error("expected an error when changing the parent of a command")
+	}
+}
+
+func TestCMD_Parse_changed(t *testing.T) {
+	cmd := kli.NewCommand("say", flag.ContinueOnError)
+	cmd.String("what", "moo", "what the cow will say")
+	cmd.Int("repeat", 1, "how many time it repeats the word")
+
+	err := cmd.Parse([]string{"-repeat", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmd.Changed("repeat") {
+		t.Error("expected repeat to be changed, even if set to it's default value")
+	}
+	if cmd.Changed("what") {
+		t.Error("expected what to hold it's default value")
+	}
+}
+
+func TestCMD_Parse_twice(t *testing.T) {
+	defer os.Unsetenv("COW_WHAT")
+	os.Setenv("COW_WHAT", "meuh")
+
+	cmd := kli.NewCommand("say", flag.ContinueOnError)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.String("what", "moo", "what the cow will say")
+	cmd.String("name", "", "the name of the cow")
+	cmd.Env("what", "COW_WHAT")
+	cmd.Required("name")
+
+	if err := cmd.Parse([]string{"-name", "daisy", "-what", "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if origin := cmd.Origin("what"); origin.Source != kli.SourceCommandLine {
+		t.Errorf("expected what to be set on the command line, got %v", origin)
+	}
+
+	if err := cmd.Parse([]string{}); err == nil {
+		t.Error("expected the required flag name to be missing on the second parse")
+	}
+	if cmd.Changed("name") {
+		t.Error("expected name not to be changed by the second parse")
+	}
+	if origin := cmd.Origin("what"); origin.Source != kli.SourceEnv {
+		t.Errorf("expected what to be read from the environment on the second parse, got %v", origin)
+	}
+	if what, _ := cmd.StringFlag("what"); what != "meuh" {
+		t.Errorf("expected the value of the environment variable, got %s", what)
+	}
+}
+
+func TestCMD_Parse_twiceDefaults(t *testing.T) {
+	cmd := kli.NewCommand("say", flag.ContinueOnError)
+	cmd.String("what", "moo", "what the cow will say")
+	cmd.StringSlice("tag", []string{"latest"}, "the tags")
+
+	if err := cmd.Parse([]string{"-what", "hi", "-tag", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Parse([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if what, _ := cmd.StringFlag("what"); what != "moo" {
+		t.Errorf("expected the default value on the second parse, got %s", what)
+	}
+	if cmd.Changed("what") || cmd.Origin("what").Source != kli.SourceDefault {
+		t.Errorf("expected what to hold it's default value, got %v", cmd.Origin("what"))
+	}
+	if tags, _ := cmd.StringSliceFlag("tag"); !reflect.DeepEqual(tags, []string{"latest"}) {
+		t.Errorf("expected the default tags on the second parse, got %v", tags)
+	}
+}
+
+func TestCMD_Parse_required(t *testing.T) {
+	cmd := kli.NewCommand("login", flag.ContinueOnError)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.String("user", "", "the user name")
+	cmd.String("password", "", "the user password")
+	cmd.Bool("verbose", false, "prints more")
+	cmd.Required("user", "password")
+
+	err := cmd.Parse([]string{"-verbose"})
+	kerr, ok := err.(kli.Error)
+	if !ok {
+		t.Fatalf("expected a kli.Error, got %v", err)
+	}
+	if kerr.Code() != kli.MisuseError {
+		t.Errorf("expected the code %d, got %d", kli.MisuseError, kerr.Code())
+	}
+	if !strings.Contains(kerr.Error(), "-user, -password") {
+		t.Errorf("expected every missing flag in the error, got %s", kerr.Error())
+	}
+
+	err = cmd.Parse([]string{"-user", "cow", "-password", "moo"})
+	if err != nil {
+		t.Errorf("expected no error, got %s", err)
+	}
+}
+
+func TestCMD_Parse_flagGroups(t *testing.T) {
+	// a new command for every case
+	newCmd := func() *kli.CMD {
+		cmd := kli.NewCommand("list", flag.ContinueOnError)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.Bool("json", false, "json output")
+		cmd.Bool("yaml", false, "yaml output")
+		cmd.String("user", "", "the user name")
+		cmd.String("password", "", "the user password")
+		cmd.String("id", "", "the item id")
+		cmd.String("name", "", "the item name")
+		cmd.MutuallyExclusive("json", "yaml")
+		cmd.RequiredTogether("user", "password")
+		cmd.OneRequired("id", "name")
+		return cmd
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-id", "1", "-json", "-yaml"}, "flags -json, -yaml are mutually exclusive"},
+		{[]string{"-name", "cow", "-user", "cow"}, "flags -user, -password must be set together, got -user"},
+		{[]string{"-json"}, "one of the flags -id, -name is required"},
+		{[]string{"-id", "1", "-json", "-user", "cow", "-password", "moo"}, ""},
+	}
+	for _, tt := range tests {
+		err := newCmd().Parse(tt.args)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%v: expected no error, got %s", tt.args, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: expected the error to contain %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestCMD_Parse_env(t *testing.T) {
+	defer os.Unsetenv("COW_WHAT")
+	defer os.Unsetenv("MOO_REPEAT")
+	defer os.Unsetenv("MYTOOL_DRY_RUN")
+	os.Setenv("COW_WHAT", "meuh")
+	os.Setenv("MOO_REPEAT", "3")
+	os.Setenv("MYTOOL_DRY_RUN", "true")
+
+	cmd := kli.NewCommand("say", flag.ContinueOnError)
+	cmd.String("what", "moo", "what the cow will say")
+	cmd.Int("repeat", 1, "how many time it repeats the word")
+	cmd.Bool("dry-run", false, "says nothing")
+	cmd.Env("what", "COW_WHAT")
+	cmd.Env("repeat", "MOO_REPEAT")
+	cmd.SetEnvPrefix("MYTOOL_")
+
+	err := cmd.Parse([]string{"-repeat", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if what, _ := cmd.StringFlag("what"); what != "meuh" {
+		t.Errorf("expected the value of the environment variable, got %s", what)
+	}
+	if repeat, _ := cmd.IntFlag("repeat"); repeat != 2 {
+		t.Errorf("expected the command line to win over the environment variable, got %d", repeat)
+	}
+	if dryRun, _ := cmd.BoolFlag("dry-run"); !dryRun {
+		t.Error("expected the prefixed environment variable to be used")
+	}
+	if !cmd.Changed("what") {
+		t.Error("expected a flag set by it's environment variable to be changed")
+	}
+	if got := cmd.EnvVar("dry-run"); got != "MYTOOL_DRY_RUN" {
+		t.Errorf("expected the environment variable MYTOOL_DRY_RUN, got %s", got)
+	}
+}
+
+func TestCMD_Parse_envInvalid(t *testing.T) {
+	defer os.Unsetenv("COW_REPEAT")
+	os.Setenv("COW_REPEAT", "twice")
+
+	cmd := kli.NewCommand("say", flag.ContinueOnError)
+	cmd.Int("repeat", 1, "how many time it repeats the word")
+	cmd.Env("repeat", "COW_REPEAT")
+
+	err := cmd.Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "COW_REPEAT") {
+		t.Errorf("expected an error naming the environment variable, got %v", err)
+	}
+}
+
+func TestCMD_Parse_duration(t *testing.T) {
+	cmd := kli.NewCommand("sleep", flag.ContinueOnError)
+	cmd.Duration("for", 90*time.Second, "how long to sleep")
+	cmd.SetOutput(ioutil.Discard)
+
+	if def := cmd.Lookup("for").DefValue; def != "1m30s" {
+		t.Errorf("expected the default to render as a duration, got %s", def)
+	}
+	if kind := cmd.Store()["for"]; kind != reflect.Int64 {
+		t.Errorf("expected the kind of time.Duration, got %s", kind)
+	}
+
+	if err := cmd.Parse([]string{"-for", "2h"}); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := cmd.DurationFlag("for"); !ok || d != 2*time.Hour {
+		t.Errorf("expected 2h, got %s", d)
+	}
+	if _, ok := cmd.Int64Flag("for"); ok {
+		t.Error("expected ok to be false reading a duration as an int64")
+	}
+	if _, ok := cmd.StringFlag("for"); ok {
+		t.Error("expected ok to be false reading a duration as a string")
+	}
+
+	cmd = kli.NewCommand("sleep", flag.ContinueOnError)
+	cmd.Duration("for", time.Second, "how long to sleep")
+	cmd.SetOutput(ioutil.Discard)
+	err := cmd.Parse([]string{"-for", "forever"})
+	if err == nil || !strings.Contains(err.Error(), "-for") || !strings.Contains(err.Error(), `time: invalid duration "forever"`) {
+		t.Errorf("expected the parse error of the duration naming the flag, got %v", err)
+	}
+}
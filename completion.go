@@ -0,0 +1,391 @@
+package kli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompletionCommandName is the name of the built-in completion command
+const CompletionCommandName = "completion"
+
+// CompleteCommandName is the name of the hidden command called by the completion scripts.
+// `prog __complete <args...> <word>` prints the candidates for the word being completed,
+// one per line, optionally followed by a tab and it's description
+const CompleteCommandName = "__complete"
+
+// the supported shells for the completion scripts
+const (
+	Bash       = "bash"
+	Zsh        = "zsh"
+	Fish       = "fish"
+	PowerShell = "powershell"
+)
+
+var shells = []string{Bash, Zsh, Fish, PowerShell}
+
+// CompletionFunc returns the completion candidates of the word being completed (toComplete)
+// args are the positional arguments already given to the command.
+// a candidate can be followed by a tab and it's description
+type CompletionFunc func(cmd Command, args []string, toComplete string) []string
+
+// CompleteFiles returns a CompletionFunc completing the file paths
+// with one of the given extensions (ex: ".json"), and the directories.
+// every file is a candidate when no extension is given
+func CompleteFiles(extensions ...string) CompletionFunc {
+	return func(_ Command, _ []string, toComplete string) []string {
+		dir, prefix := filepath.Split(toComplete)
+		read := dir
+		if read == "" {
+			read = "."
+		}
+		files, err := ioutil.ReadDir(read)
+		if err != nil {
+			return nil
+		}
+
+		var candidates []string
+		for _, f := range files {
+			if !strings.HasPrefix(f.Name(), prefix) {
+				continue
+			}
+			if f.IsDir() {
+				candidates = append(candidates, dir+f.Name()+string(filepath.Separator))
+				continue
+			}
+			if hasExtension(f.Name(), extensions) {
+				candidates = append(candidates, dir+f.Name())
+			}
+		}
+		return candidates
+	}
+}
+
+func hasExtension(name string, extensions []string) bool {
+	if len(extensions) == 0 {
+		return true
+	}
+	for _, ext := range extensions {
+		if filepath.Ext(name) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// EnableCompletion adds the `completion <shell>` sub-command to the root command
+// it writes the completion script of the shell to the standard output.
+// the scripts call back the hidden `__complete` command to compute the candidates
+func (a *App) EnableCompletion() {
+	a.completion = true
+}
+
+// NewCompletionCommand returns the `completion <shell>` command
+// generating the completion scripts for the root command
+func NewCompletionCommand(root Command) *CMD {
+	cmd := NewCommand(CompletionCommandName, flag.ContinueOnError)
+	cmd.Description(fmt.Sprintf("generates the completion script for %s", strings.Join(shells, ", ")))
+	cmd.Detail(strings.NewReader(strings.Join([]string{
+		fmt.Sprintf("%s %s <%s>", root.Name(), CompletionCommandName, strings.Join(shells, "|")),
+		"",
+		"bash:       source <(" + root.Name() + " completion bash)",
+		"zsh:        " + root.Name() + " completion zsh > \"${fpath[1]}/_" + root.Name() + "\"",
+		"fish:       " + root.Name() + " completion fish > ~/.config/fish/completions/" + root.Name() + ".fish",
+		"powershell: " + root.Name() + " completion powershell | Out-String | Invoke-Expression",
+	}, "\n")))
+	cmd.CompleteArgs(func(_ Command, args []string, _ string) []string {
+		if len(args) > 0 {
+			return nil
+		}
+		return shells
+	})
+	cmd.Do(func(cmd Command, _ KFlag) Error {
+		args := cmd.Args()
+		if len(args) != 1 {
+			return NewErrorf(MisuseError, "expected one shell: %s", strings.Join(shells, ", "))
+		}
+		err := GenCompletion(os.Stdout, root, args[0])
+		if err != nil {
+			return ErrorWrap(err, "could not generate the completion script", MisuseError)
+		}
+		return nil
+	})
+	return cmd
+}
+
+// GenCompletion writes the completion script of the shell for the root command.
+// the script calls the program with the hidden `__complete` command on completion
+func GenCompletion(w io.Writer, root Command, shell string) error {
+	switch shell {
+	case Bash:
+		return genBashCompletion(w, root.Name())
+	case Zsh:
+		return genZshCompletion(w, root.Name())
+	case Fish:
+		return genFishCompletion(w, root.Name())
+	case PowerShell:
+		return genPowerShellCompletion(w, root.Name())
+	}
+	return fmt.Errorf("unsupported shell %q, expected one of: %s", shell, strings.Join(shells, ", "))
+}
+
+// Complete writes the completion candidates to w, one per line.
+// args are the arguments following the program name,
+// the last one is the word being completed, it can be empty
+func (a *App) Complete(w io.Writer, args []string) error {
+	toComplete := ""
+	if len(args) > 0 {
+		args, toComplete = args[:len(args)-1], args[len(args)-1]
+	}
+
+	cmd := a.root
+	var positional []string
+	// pending is the flag waiting for the word being completed as it's value
+	var pending *flag.Flag
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case strings.HasPrefix(arg, "-") && len(positional) == 0:
+			if f := valueFlag(cmd, arg); f != nil {
+				// the next argument is the flag value, bash splits
+				// --flag=value in the words --flag, = and value
+				i++
+				if i < len(args) && args[i] == "=" {
+					i++
+				}
+				if i == len(args) {
+					pending = f
+				}
+			}
+		case len(positional) == 0 && childNamed(cmd, arg) != nil:
+			cmd = childNamed(cmd, arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if pending != nil && toComplete == "=" {
+		// bash completes --flag= with the words --flag and =, the value is empty
+		toComplete = ""
+	}
+
+	var candidates []string
+	switch {
+	case pending != nil:
+		candidates = completeFlag(cmd, pending.Name, positional, toComplete)
+	case strings.HasPrefix(toComplete, "-") && strings.Contains(toComplete, "="):
+		parts := strings.SplitN(toComplete, "=", 2)
+		name := strings.TrimLeft(parts[0], "-")
+		if r := []rune(name); len(r) == 1 && cmd.Lookup(name) == nil {
+			if f := cmd.LookupShort(r[0]); f != nil {
+				name = f.Name
+			}
+		}
+		for _, c := range completeFlag(cmd, name, positional, parts[1]) {
+			candidates = append(candidates, parts[0]+"="+c)
+		}
+	case strings.HasPrefix(toComplete, "--"):
+		cmd.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, "--"+f.Name+"\t"+f.Usage)
+			if cmd.IsNegatable(f.Name) {
+				candidates = append(candidates, "--no-"+f.Name+"\t"+f.Usage)
+			}
+		})
+	case strings.HasPrefix(toComplete, "-"):
+		cmd.VisitAll(func(f *flag.Flag) {
+			if short := cmd.GetShort(f.Name); short != 0 {
+				candidates = append(candidates, "-"+string(short)+"\t"+f.Usage)
+			}
+			candidates = append(candidates, "-"+f.Name+"\t"+f.Usage)
+		})
+	default:
+		if len(positional) == 0 {
+			for _, child := range cmd.Children() {
+				candidates = append(candidates, child.Name()+"\t"+child.GetDescription())
+			}
+		}
+		if fn := cmd.ArgsCompletion(); fn != nil {
+			candidates = append(candidates, fn(cmd, positional, toComplete)...)
+		}
+	}
+
+	for _, c := range candidates {
+		if !strings.HasPrefix(c, toComplete) {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// valueFlag returns the flag of the argument if it's value is the next argument
+// the short flags are resolved, for a bundle -xvf it's the last one
+func valueFlag(cmd Command, arg string) *flag.Flag {
+	name := strings.TrimLeft(arg, "-")
+	if strings.Contains(name, "=") {
+		return nil
+	}
+	if f := cmd.Lookup(name); f != nil || strings.HasPrefix(arg, "--") {
+		if f != nil && !isBoolFlag(f) {
+			return f
+		}
+		return nil
+	}
+
+	for i, short := range name {
+		f := cmd.LookupShort(short)
+		if f == nil {
+			return nil
+		}
+		if !isBoolFlag(f) {
+			if i+len(string(short)) == len(name) {
+				return f
+			}
+			// the value is attached
+			return nil
+		}
+	}
+	return nil
+}
+
+// completeFlag returns the candidates for the value of the flag "name"
+// the choices of an Enum flag, unless a completion function is set
+func completeFlag(cmd Command, name string, args []string, toComplete string) []string {
+	fn := cmd.FlagCompletion(name)
+	if fn == nil {
+		return cmd.Choices(name)
+	}
+	return fn(cmd, args, toComplete)
+}
+
+// childNamed returns the child command with the given name or alias, nil if none
+func childNamed(cmd Command, name string) Command {
+	for _, c := range cmd.Children() {
+		if isNamed(c, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// isBoolFlag returns true if the flag does not need a value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// funcName returns a shell function name for the program
+func funcName(name string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func genBashCompletion(w io.Writer, name string) error {
+	fn := funcName(name)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# bash completion for %s\n\n", name)
+	fmt.Fprintf(b, "%s()\n{\n", fn)
+	fmt.Fprintf(b, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" line\n")
+	// = is a word break, --flag=<TAB> completes the word =
+	fmt.Fprintf(b, "    [[ \"${cur}\" == \"=\" ]] && cur=\"\"\n")
+	fmt.Fprintf(b, "    COMPREPLY=()\n")
+	fmt.Fprintf(b, "    while IFS='' read -r line; do\n")
+	fmt.Fprintf(b, "        line=\"${line%%%%$'\\t'*}\"\n")
+	fmt.Fprintf(b, "        [[ \"${line}\" == \"${cur}\"* ]] && COMPREPLY+=(\"${line}\")\n")
+	fmt.Fprintf(b, "    done < <(\"${COMP_WORDS[0]}\" %s \"${COMP_WORDS[@]:1:COMP_CWORD}\" 2>/dev/null)\n", CompleteCommandName)
+	fmt.Fprintf(b, "}\n\n")
+	fmt.Fprintf(b, "complete -o default -F %s %s\n", fn, name)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func genZshCompletion(w io.Writer, name string) error {
+	fn := funcName(name)
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "#compdef %s\n\n", name)
+	fmt.Fprintf(b, "# zsh completion for %s\n\n", name)
+	fmt.Fprintf(b, "%s()\n{\n", fn)
+	fmt.Fprintf(b, "    local -a candidates\n")
+	fmt.Fprintf(b, "    local line word\n")
+	fmt.Fprintf(b, "    for line in \"${(@f)$(\"${words[1]}\" %s \"${(@)words[2,CURRENT]}\" 2>/dev/null)}\"; do\n", CompleteCommandName)
+	fmt.Fprintf(b, "        [[ -z \"${line}\" ]] && continue\n")
+	fmt.Fprintf(b, "        word=\"${line%%%%$'\\t'*}\"\n")
+	fmt.Fprintf(b, "        if [[ \"${line}\" == *$'\\t'* ]]; then\n")
+	fmt.Fprintf(b, "            candidates+=(\"${word//:/\\\\:}:${line#*$'\\t'}\")\n")
+	fmt.Fprintf(b, "        else\n")
+	fmt.Fprintf(b, "            candidates+=(\"${word//:/\\\\:}\")\n")
+	fmt.Fprintf(b, "        fi\n")
+	fmt.Fprintf(b, "    done\n\n")
+	fmt.Fprintf(b, "    if (( ${#candidates} )); then\n")
+	fmt.Fprintf(b, "        _describe 'values' candidates\n")
+	fmt.Fprintf(b, "    else\n")
+	fmt.Fprintf(b, "        _files\n")
+	fmt.Fprintf(b, "    fi\n")
+	fmt.Fprintf(b, "}\n\n")
+	fmt.Fprintf(b, "compdef %s %s\n", fn, name)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func genFishCompletion(w io.Writer, name string) error {
+	fn := "_" + funcName(name) + "_complete"
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# fish completion for %s\n\n", name)
+	fmt.Fprintf(b, "function %s\n", fn)
+	fmt.Fprintf(b, "    set -l tokens (commandline -opc)\n")
+	fmt.Fprintf(b, "    set -l current (commandline -ct)\n")
+	fmt.Fprintf(b, "    set -l candidates ($tokens[1] %s $tokens[2..-1] \"$current\" 2>/dev/null)\n", CompleteCommandName)
+	fmt.Fprintf(b, "    if test (count $candidates) -eq 0\n")
+	fmt.Fprintf(b, "        __fish_complete_path \"$current\"\n")
+	fmt.Fprintf(b, "        return\n")
+	fmt.Fprintf(b, "    end\n")
+	fmt.Fprintf(b, "    printf '%%s\\n' $candidates\n")
+	fmt.Fprintf(b, "end\n\n")
+	fmt.Fprintf(b, "complete -c %s -f -a '(%s)'\n", name, fn)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func genPowerShellCompletion(w io.Writer, name string) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# powershell completion for %s\n\n", name)
+	fmt.Fprintf(b, "Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {\n", strings.Replace(name, "'", "''", -1))
+	fmt.Fprintf(b, "    param($wordToComplete, $commandAst, $cursorPosition)\n\n")
+	fmt.Fprintf(b, "    $program = $commandAst.CommandElements[0].ToString()\n")
+	fmt.Fprintf(b, "    $arguments = @('%s')\n", CompleteCommandName)
+	fmt.Fprintf(b, "    foreach ($element in $commandAst.CommandElements | Select-Object -Skip 1) {\n")
+	fmt.Fprintf(b, "        if ($element.Extent.EndOffset -ge $cursorPosition) {\n")
+	fmt.Fprintf(b, "            break\n")
+	fmt.Fprintf(b, "        }\n")
+	fmt.Fprintf(b, "        $arguments += $element.ToString()\n")
+	fmt.Fprintf(b, "    }\n")
+	fmt.Fprintf(b, "    # before 7.3 empty arguments are not passed to native commands\n")
+	fmt.Fprintf(b, "    if ($wordToComplete -eq '' -and $PSVersionTable.PSVersion -lt [version]'7.3') {\n")
+	fmt.Fprintf(b, "        $arguments += '\"\"'\n")
+	fmt.Fprintf(b, "    } else {\n")
+	fmt.Fprintf(b, "        $arguments += $wordToComplete\n")
+	fmt.Fprintf(b, "    }\n\n")
+	fmt.Fprintf(b, "    & $program @arguments 2>$null | ForEach-Object {\n")
+	fmt.Fprintf(b, "        $word, $desc = $_ -split \"`t\", 2\n")
+	fmt.Fprintf(b, "        if (-not $desc) {\n")
+	fmt.Fprintf(b, "            $desc = $word\n")
+	fmt.Fprintf(b, "        }\n")
+	fmt.Fprintf(b, "        [System.Management.Automation.CompletionResult]::new($word, $word, 'ParameterValue', $desc)\n")
+	fmt.Fprintf(b, "    }\n")
+	fmt.Fprintf(b, "}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,102 @@
+package kli_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"strings"
+	"testing"
+)
+
+func completionApp(t *testing.T) *kli.App {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.Bool("eat", false, "informs the cow to eat")
+
+	sub := kli.NewCommand("say", flag.ContinueOnError)
+	sub.Description("makes the cow talk")
+	sub.String("what", "mooooo", "what the cow will say")
+	sub.Int("repeat", 1, "how many time it repeats the word")
+	sub.CompleteFlag("what", func(_ kli.Command, _ []string, _ string) []string {
+		return []string{"moo", "meuh"}
+	})
+	sub.CompleteArgs(func(_ kli.Command, args []string, _ string) []string {
+		return []string{"friend", "farmer"}
+	})
+
+	err := root.SetChildren(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.EnableCompletion()
+	// the built-in commands are added on execution
+	_, _ = app.Execute(kli.NewContext().SetArgs([]string{"-eat"}))
+	return app
+}
+
+func TestGenCompletion(t *testing.T) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+
+	tests := map[string][]string{
+		kli.Bash:       {"complete -o default -F _cow cow", "__complete", `[[ "${cur}" == "=" ]] && cur=""`},
+		kli.Zsh:        {"#compdef cow", "compdef _cow cow", "__complete"},
+		kli.Fish:       {"complete -c cow -f -a '(__cow_complete)'", "__complete"},
+		kli.PowerShell: {"-CommandName 'cow'", "__complete"},
+	}
+
+	for shell, mustFind := range tests {
+		b := &bytes.Buffer{}
+		err := kli.GenCompletion(b, root, shell)
+		if err != nil {
+			t.Fatalf("%s: %s", shell, err)
+		}
+		for _, str := range mustFind {
+			if !strings.Contains(b.String(), str) {
+				t.Errorf("%s: could not find \"%s\" in \n%s", shell, str, b.String())
+			}
+		}
+	}
+}
+
+func TestGenCompletion_unsupportedShell(t *testing.T) {
+	err := kli.GenCompletion(&bytes.Buffer{}, kli.NewCommand("cow", flag.ContinueOnError), "tcsh")
+	if err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestApp_Complete(t *testing.T) {
+	app := completionApp(t)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{""}, []string{"say\tmakes the cow talk", "completion\tgenerates the completion script for bash, zsh, fish, powershell"}},
+		{[]string{"-eat", "s"}, []string{"say\tmakes the cow talk"}},
+		{[]string{"-"}, []string{"-eat\tinforms the cow to eat"}},
+		{[]string{"say", "-r"}, []string{"-repeat\thow many time it repeats the word"}},
+		{[]string{"say", "-what", ""}, []string{"moo", "meuh"}},
+		{[]string{"say", "-what=m"}, []string{"-what=moo", "-what=meuh"}},
+		// bash splits --what= in the words --what and =, --what=m in --what, = and m
+		{[]string{"say", "--what", "="}, []string{"moo", "meuh"}},
+		{[]string{"say", "--what", "=", "me"}, []string{"meuh"}},
+		{[]string{"say", "--what", "=", "hi", "f"}, []string{"friend", "farmer"}},
+		{[]string{"say", "-repeat", "2", "f"}, []string{"friend", "farmer"}},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+	}
+
+	for _, tt := range tests {
+		b := &bytes.Buffer{}
+		err := app.Complete(b, tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Join(tt.want, "\n") + "\n"
+		if b.String() != want {
+			t.Errorf("%v: expected %q, got %q", tt.args, want, b.String())
+		}
+	}
+}
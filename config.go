@@ -0,0 +1,415 @@
+package kli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigFlag is the name of the built-in flag selecting the configuration file
+// see App::EnableConfigFlag
+const ConfigFlag = "config"
+
+// Config holds the flag values read from a configuration file.
+// the sections mirror the command path without the root name:
+// the root flags are at the top level, the flags of `cow say` in the section "say"
+// and the ones of `cow say third` in the section "say.third"
+type Config struct {
+	// Path is the file the configuration was read from
+	Path string
+	// Sections maps the section name to the flag values
+	Sections map[string]map[string][]string
+}
+
+// LoadConfig reads the configuration file
+// a .json file is read as JSON, any other as INI/TOML
+//
+// JSON: the root flags are at the top level, the objects named after a sub-command
+// hold it's flags. INI/TOML: the root flags are before the first section,
+// the section [say.third] holds the flags of `cow say third`.
+// an array sets the flag once per value
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections map[string]map[string][]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		sections, err = parseJSONConfig(b)
+	} else {
+		sections, err = parseINIConfig(bytes.NewReader(b))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the configuration file %s: %s", path, err.Error())
+	}
+	return &Config{Path: path, Sections: sections}, nil
+}
+
+// apply sets the configuration of every command of the tree
+// the unknown sections and flags are returned as an error
+func (c *Config) apply(root Command) error {
+	commands := map[string]Command{}
+	var walk func(cmd Command)
+	walk = func(cmd Command) {
+		commands[strings.Join(CommandPath(cmd)[1:], ".")] = cmd
+		for _, child := range cmd.Children() {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	// a JSON object that is not a command is the value of a map flag
+	for section, values := range c.Sections {
+		if _, ok := commands[section]; ok {
+			continue
+		}
+		parentSection, name := "", section
+		if i := strings.LastIndex(section, "."); i != -1 {
+			parentSection, name = section[:i], section[i+1:]
+		}
+		parent, ok := commands[parentSection]
+		if !ok || parent.Lookup(name) == nil {
+			continue
+		}
+		if c.Sections[parentSection] == nil {
+			c.Sections[parentSection] = map[string][]string{}
+		}
+		for k, vs := range values {
+			for _, v := range vs {
+				c.Sections[parentSection][name] = append(c.Sections[parentSection][name], k+"="+v)
+			}
+		}
+		delete(c.Sections, section)
+	}
+
+	var unknown []string
+	for section, values := range c.Sections {
+		cmd, ok := commands[section]
+		if !ok {
+			unknown = append(unknown, fmt.Sprintf("[%s]", section))
+			continue
+		}
+		for name := range values {
+			if cmd.Lookup(name) == nil {
+				unknown = append(unknown, configKey(section, name))
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keys in the configuration file %s: %s", c.Path, strings.Join(unknown, ", "))
+	}
+
+	for section, cmd := range commands {
+		cmd.SetConfig(c.Path, c.Sections[section])
+	}
+	return nil
+}
+
+func configKey(section, name string) string {
+	if section == "" {
+		return name
+	}
+	return section + "." + name
+}
+
+// SetConfigFile sets the configuration file of the app
+// the file is optional, it is ignored if it does not exist.
+// the --config flag, see EnableConfigFlag, or it's environment variable takes precedence
+func (a *App) SetConfigFile(path string) {
+	a.configFile = path
+}
+
+// EnableConfigFlag adds the --config flag to the root command
+// selecting the configuration file
+func (a *App) EnableConfigFlag() {
+	a.configFlag = true
+}
+
+// loadConfig loads the configuration file given by the --config flag, or
+// it's environment variable, or set by SetConfigFile, and sets it on the commands of the tree
+func (a *App) loadConfig(args []string) error {
+	path, required := a.configFile, false
+	if a.configFlag {
+		if p, ok := scanFlag(a.root, ConfigFlag, args); ok {
+			path, required = p, true
+		} else if p, ok := lookupEnv(a.root, ConfigFlag); ok {
+			path, required = p, true
+		}
+	}
+	if path == "" {
+		return nil
+	}
+
+	config, err := LoadConfig(path)
+	if os.IsNotExist(err) && !required {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return config.apply(a.root)
+}
+
+// lookupEnv returns the value of the environment variable bound to the flag "name"
+// ok is false if the flag is not bound to a variable or if it's not set
+func lookupEnv(cmd Command, name string) (value string, ok bool) {
+	envVar := cmd.EnvVar(name)
+	if envVar == "" {
+		return "", false
+	}
+	return os.LookupEnv(envVar)
+}
+
+// scanFlag returns the value of the flag "name" in the flags of the command
+// the short names and bundles are resolved like CMD::Parse does.
+// it stops at the first non flag argument
+func scanFlag(cmd Command, name string, args []string) (string, bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			return "", false
+		}
+
+		parts := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)
+		f, value, attached := cmd.Lookup(parts[0]), "", len(parts) == 2
+		if attached {
+			value = parts[1]
+		}
+		if f == nil && !strings.HasPrefix(arg, "--") {
+			f, value, attached = scanShorts(cmd, []rune(arg[1:]))
+		}
+		if f == nil || isBoolFlag(f) {
+			continue
+		}
+
+		if f.Name == name {
+			if attached {
+				return value, true
+			}
+			if i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", false
+		}
+		if !attached {
+			// skip the flag value
+			i++
+		}
+	}
+	return "", false
+}
+
+// scanShorts returns the flag of a bundle of short names taking a value
+// and the value when it's attached, ex: -vo out or -voout
+func scanShorts(cmd Command, letters []rune) (f *flag.Flag, value string, attached bool) {
+	for i, short := range letters {
+		f := cmd.LookupShort(short)
+		if f == nil {
+			return nil, "", false
+		}
+		rest := string(letters[i+1:])
+		if isBoolFlag(f) && !strings.HasPrefix(rest, "=") {
+			continue
+		}
+		return f, strings.TrimPrefix(rest, "="), rest != ""
+	}
+	return nil, "", false
+}
+
+// SetConfig sets the flag values read from the configuration file at path
+func (c *CMD) SetConfig(path string, values map[string][]string) {
+	c.configPath = path
+	c.config = values
+}
+
+// parseConfig sets the flags not set on the command line, or by their environment
+// variable, from the configuration file
+func (c *CMD) parseConfig() error {
+	var err error
+	section := strings.Join(CommandPath(c)[1:], ".")
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		values, ok := c.config[f.Name]
+		if err != nil || !ok || c.KFlag.Changed(f.Name) {
+			return
+		}
+		for _, value := range values {
+			if serr := f.Value.Set(value); serr != nil {
+				err = NewErrorf(MisuseError, "invalid value %q in the configuration file %s for flag -%s: %s", value, c.configPath, f.Name, serr.Error())
+				return
+			}
+		}
+		c.KFlag.SetOrigin(f.Name, Origin{Source: SourceConfig, Detail: c.configPath + ": " + configKey(section, f.Name)})
+	})
+	return err
+}
+
+// parseJSONConfig flattens the JSON objects into sections
+// an object is a section unless it's the value of a flag in a section
+func parseJSONConfig(b []byte) (map[string]map[string][]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var root map[string]interface{}
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	sections := map[string]map[string][]string{}
+	var flatten func(section string, obj map[string]interface{}) error
+	flatten = func(section string, obj map[string]interface{}) error {
+		values := map[string][]string{}
+		sections[section] = values
+		for key, v := range obj {
+			switch value := v.(type) {
+			case map[string]interface{}:
+				if err := flatten(configKey(section, key), value); err != nil {
+					return err
+				}
+			case []interface{}:
+				for _, elem := range value {
+					s, err := jsonScalar(elem)
+					if err != nil {
+						return fmt.Errorf("%s: %s", configKey(section, key), err.Error())
+					}
+					values[key] = append(values[key], s)
+				}
+			default:
+				s, err := jsonScalar(value)
+				if err != nil {
+					return fmt.Errorf("%s: %s", configKey(section, key), err.Error())
+				}
+				values[key] = []string{s}
+			}
+		}
+		return nil
+	}
+	return sections, flatten("", root)
+}
+
+func jsonScalar(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
+
+// parseINIConfig reads the INI/TOML style configuration
+// key = value lines grouped by [section], # and ; start a comment.
+// the values can be quoted, "double" with escapes or 'single' as is,
+// and arrays of values are written [a, "b"]
+func parseINIConfig(r io.Reader) (map[string]map[string][]string, error) {
+	sections := map[string]map[string][]string{"": {}}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section %s", n, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sections[section]; !ok {
+				sections[section] = map[string][]string{}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected key = value, got %s", n, line)
+		}
+		key := strings.TrimSpace(parts[0])
+		values, err := iniValues(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err.Error())
+		}
+		sections[section][key] = append(sections[section][key], values...)
+	}
+	return sections, scanner.Err()
+}
+
+// iniValues returns the value, or the values of an array
+func iniValues(raw string) ([]string, error) {
+	if !strings.HasPrefix(raw, "[") {
+		v, _, err := iniValue(raw, "#;")
+		return []string{v}, err
+	}
+
+	raw = strings.TrimSpace(raw[1:])
+	var values []string
+	for {
+		if strings.HasPrefix(raw, "]") {
+			return values, nil
+		}
+		v, rest, err := iniValue(raw, ",]#;")
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+
+		rest = strings.TrimSpace(rest)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			raw = strings.TrimSpace(rest[1:])
+		case strings.HasPrefix(rest, "]"):
+			raw = rest
+		default:
+			return nil, fmt.Errorf("unterminated array")
+		}
+	}
+}
+
+// iniValue returns the first value of raw and what follows it
+// an unquoted value ends at the first of the terminators, in an array
+// at the comma or the closing bracket, otherwise at the comment
+func iniValue(raw, terminators string) (value, rest string, err error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := 1
+		for ; end < len(raw); end++ {
+			if raw[end] == '\\' {
+				end++
+				continue
+			}
+			if raw[end] == '"' {
+				break
+			}
+		}
+		if end >= len(raw) {
+			return "", "", fmt.Errorf("unterminated string %s", raw)
+		}
+		value, err = strconv.Unquote(raw[:end+1])
+		return value, raw[end+1:], err
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end == -1 {
+			return "", "", fmt.Errorf("unterminated string %s", raw)
+		}
+		return raw[1 : end+1], raw[end+2:], nil
+	}
+
+	end := strings.IndexAny(raw, terminators)
+	if end == -1 {
+		end = len(raw)
+	}
+	return strings.TrimSpace(raw[:end]), raw[end:], nil
+}
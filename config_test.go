@@ -0,0 +1,250 @@
+package kli_test
+
+import (
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "kli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func configApp(t *testing.T) (*kli.App, map[string]string) {
+	got := map[string]string{}
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.String("name", "bessie", "the name of the cow")
+	root.Short("name", 'n')
+
+	say := kli.NewCommand("say", flag.ContinueOnError)
+	say.String("what", "moo", "what the cow will say")
+	say.Int("repeat", 1, "how many time it repeats the word")
+
+	loud := kli.NewCommand("loud", flag.ContinueOnError)
+	loud.String("volume", "low", "how loud")
+	loud.Do(func(cmd kli.Command, globals kli.KFlag) kli.Error {
+		got["name"], _ = globals.StringFlag("name")
+		got["volume"], _ = cmd.StringFlag("volume")
+		return nil
+	})
+
+	say.Do(func(cmd kli.Command, globals kli.KFlag) kli.Error {
+		got["name"], _ = globals.StringFlag("name")
+		got["what"], _ = cmd.StringFlag("what")
+		return nil
+	})
+
+	if err := say.SetChildren(loud); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.SetChildren(say); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.EnableConfigFlag()
+	return app, got
+}
+
+func TestApp_Execute_config(t *testing.T) {
+	path := writeConfig(t, "cow.toml", `
+# the globals
+name = "daisy"
+
+[say]
+what = 'meuh' ; in french
+repeat = 2
+
+[say.loud]
+volume = high
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	tests := []struct {
+		name string
+		args []string
+		env  string
+		want map[string]string
+	}{
+		{
+			"config",
+			[]string{"--config", path, "say", "loud"},
+			"",
+			map[string]string{"name": "daisy", "volume": "high"},
+		},
+		{
+			"flag over config",
+			[]string{"-config=" + path, "-name", "rosie", "say", "-what", "hi"},
+			"",
+			map[string]string{"name": "rosie", "what": "hi"},
+		},
+		{
+			"short flag before config",
+			[]string{"-n", "rosie", "--config", path, "say"},
+			"",
+			map[string]string{"name": "rosie", "what": "meuh"},
+		},
+		{
+			"attached short flag before config",
+			[]string{"-nrosie", "--config", path, "say"},
+			"",
+			map[string]string{"name": "rosie", "what": "meuh"},
+		},
+		{
+			"env over config",
+			[]string{"-config", path, "say"},
+			"moo moo",
+			map[string]string{"name": "daisy", "what": "moo moo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, got := configApp(t)
+			if tt.env != "" {
+				defer os.Unsetenv("COW_WHAT")
+				os.Setenv("COW_WHAT", tt.env)
+				app.SetEnvPrefix("COW_")
+			}
+
+			code, err := app.Execute(kli.NewContext().SetArgs(tt.args))
+			if code != kli.OK || err != nil {
+				t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestApp_Execute_configEnv(t *testing.T) {
+	path := writeConfig(t, "cow.ini", "name = daisy\n[say]\nwhat = meuh\n")
+	defer os.RemoveAll(filepath.Dir(path))
+	defer os.Unsetenv("COW_CONFIG")
+	os.Setenv("COW_CONFIG", path)
+
+	app, got := configApp(t)
+	app.SetEnvPrefix("COW_")
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"say"}))
+	if code != kli.OK || err != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, err)
+	}
+	want := map[string]string{"name": "daisy", "what": "meuh"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the configuration file of COW_CONFIG %v, got %v", want, got)
+	}
+}
+
+func TestApp_Execute_configFile(t *testing.T) {
+	path := writeConfig(t, "cow.json", `{"name": "daisy", "say": {"what": "meuh", "loud": {"volume": "high"}}}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	app, got := configApp(t)
+	app.SetConfigFile(path)
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"say"}))
+	if code != kli.OK || err != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, err)
+	}
+	if got["name"] != "daisy" || got["what"] != "meuh" {
+		t.Errorf("expected the values of the configuration file, got %v", got)
+	}
+
+	app, _ = configApp(t)
+	app.SetConfigFile(filepath.Join(filepath.Dir(path), "missing.json"))
+	if code, err := app.Execute(kli.NewContext().SetArgs([]string{"say"})); code != kli.OK || err != nil {
+		t.Errorf("expected a missing app configuration file to be ignored, got %d and %v", code, err)
+	}
+
+	app, _ = configApp(t)
+	code, err = app.Execute(kli.NewContext().SetArgs([]string{"-config", "missing.json", "say"}))
+	if code != kli.MisuseError || err == nil {
+		t.Errorf("expected code %d for a missing --config file, got %d and %v", kli.MisuseError, code, err)
+	}
+}
+
+func TestApp_Execute_configUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "cow.ini", `
+nme = daisy, bessie # typo
+[say]
+what = meuh
+[say.quiet]
+volume = low
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	app, _ := configApp(t)
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"-config", path, "say"}))
+	if code != kli.MisuseError || err == nil {
+		t.Fatalf("expected code %d with an error, got %d and %v", kli.MisuseError, code, err)
+	}
+	for _, key := range []string{"nme", "[say.quiet]"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected the error to name the unknown key %s, got %s", key, err.Error())
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, "cow.toml", `
+tags = ["a", 'b,c', d]
+[say]
+what = "say \"moo\""
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	config, err := kli.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string][]string{
+		"":    {"tags": {"a", "b,c", "d"}},
+		"say": {"what": {`say "moo"`}},
+	}
+	if !reflect.DeepEqual(config.Sections, want) {
+		t.Errorf("expected %v, got %v", want, config.Sections)
+	}
+}
+
+func TestApp_Execute_configMap(t *testing.T) {
+	path := writeConfig(t, "run.json", `{"tag": ["a", "b"], "label": {"env": "prod"}}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	var tags []string
+	var labels map[string]string
+	root := kli.NewCommand("run", flag.ContinueOnError)
+	root.StringSlice("tag", nil, "the image tags")
+	root.StringMap("label", nil, "the labels")
+	root.Do(func(cmd kli.Command, _ kli.KFlag) kli.Error {
+		tags, _ = cmd.StringSliceFlag("tag")
+		labels, _ = cmd.StringMapFlag("label")
+		return nil
+	})
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.SetConfigFile(path)
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"-tag", "c"}))
+	if code != kli.OK || err != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, err)
+	}
+	if !reflect.DeepEqual(tags, []string{"c"}) {
+		t.Errorf("expected the command line to win over the configuration, got %v", tags)
+	}
+	if !reflect.DeepEqual(labels, map[string]string{"env": "prod"}) {
+		t.Errorf("expected the labels of the configuration, got %v", labels)
+	}
+}
@@ -1,16 +1,57 @@
 package kli
 
-import "os"
+import (
+	"context"
+	"os"
+	"time"
+)
 
-// todo create a proper context with timeout
-// that has similar functionality as the http.context
-// else it's a bit confusing
+// Context is the execution context of the app.
+// It carries the argument list and wraps a context.Context
+// that is handed to the executed command (see Command.Context)
+// so handlers can observe cancellation, deadlines and values
 type Context struct {
+	context.Context
 	args []string
 }
 
+// NewContext returns a new Context with a background context.Context
 func NewContext() *Context {
-	return &Context{}
+	return &Context{Context: context.Background()}
+}
+
+// SetContext sets the parent context.Context
+func (c *Context) SetContext(ctx context.Context) *Context {
+	c.Context = ctx
+	return c
+}
+
+// WithCancel makes the context cancellable
+// the returned function cancels it
+func (c *Context) WithCancel() context.CancelFunc {
+	var cancel context.CancelFunc
+	c.Context, cancel = context.WithCancel(c.parent())
+	return cancel
+}
+
+// WithTimeout cancels the context after the duration d
+func (c *Context) WithTimeout(d time.Duration) context.CancelFunc {
+	var cancel context.CancelFunc
+	c.Context, cancel = context.WithTimeout(c.parent(), d)
+	return cancel
+}
+
+// WithDeadline cancels the context at the time d
+func (c *Context) WithDeadline(d time.Time) context.CancelFunc {
+	var cancel context.CancelFunc
+	c.Context, cancel = context.WithDeadline(c.parent(), d)
+	return cancel
+}
+
+// WithValue associates the value val with the key
+func (c *Context) WithValue(key, val interface{}) *Context {
+	c.Context = context.WithValue(c.parent(), key, val)
+	return c
 }
 
 func (c *Context) SetArgs(args []string) *Context {
@@ -30,3 +71,12 @@ func (c *Context) Default() *Context {
 func (c *Context) Args() []string {
 	return c.args
 }
+
+// parent returns the wrapped context.Context
+// a zero value Context falls back to the background context
+func (c *Context) parent() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
+}
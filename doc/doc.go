@@ -0,0 +1,294 @@
+// Package doc generates the reference documentation of a kli command tree
+// as Markdown, one page per command, or as a single HTML file
+package doc
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/SamuelTissot/kli"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// page holds the documentation of a command
+type page struct {
+	Name        string
+	Path        string
+	Description string
+	Detail      string
+	Usage       string
+	Args        []kli.Arg
+	Flags       []flagDoc
+	Globals     []flagDoc
+	Parent      *link
+	Children    []link
+}
+
+type flagDoc struct {
+	Name    string
+	Type    string
+	Default string
+	Usage   string
+}
+
+type link struct {
+	Path        string
+	Name        string
+	Description string
+}
+
+// GenMarkdownTree writes one Markdown page per command to the directory dir
+// starting with the root command and walking it's children.
+// the page of the command `cow say` is named cow-say.md
+func GenMarkdownTree(root kli.Command, dir string) error {
+	f, err := os.Create(filepath.Join(dir, pageName(root)+".md"))
+	if err != nil {
+		return err
+	}
+
+	err = GenMarkdown(f, root)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, child := range root.Children() {
+		if err := GenMarkdownTree(child, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenMarkdown writes the Markdown page of the command to w
+// the links to the parent and children pages are relative
+func GenMarkdown(w io.Writer, cmd kli.Command) error {
+	p := newPage(cmd)
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, "# %s\n\n", p.Path)
+	if p.Description != "" {
+		fmt.Fprintf(b, "%s\n\n", p.Description)
+	}
+	fmt.Fprintf(b, "## Usage\n\n```\n%s\n```\n\n", p.Usage)
+	if p.Detail != "" {
+		fmt.Fprintf(b, "## Details\n\n```\n%s\n```\n\n", strings.TrimRight(p.Detail, "\n"))
+	}
+	if len(p.Args) > 0 {
+		fmt.Fprintf(b, "## Arguments\n\n")
+		fmt.Fprintf(b, "| Argument | Type | Description |\n")
+		fmt.Fprintf(b, "|----------|------|-------------|\n")
+		for _, arg := range p.Args {
+			fmt.Fprintf(b, "| `%s` | %s | %s |\n", cell(arg.String()), arg.Type, cell(arg.Usage))
+		}
+		fmt.Fprintf(b, "\n")
+	}
+	if len(p.Flags) > 0 {
+		fmt.Fprintf(b, "## Flags\n\n")
+		markdownFlags(b, p.Flags)
+	}
+	if len(p.Globals) > 0 {
+		fmt.Fprintf(b, "## Global flags\n\n")
+		markdownFlags(b, p.Globals)
+	}
+	if len(p.Children) > 0 {
+		fmt.Fprintf(b, "## Commands\n\n")
+		for _, l := range p.Children {
+			markdownLink(b, l)
+		}
+		fmt.Fprintf(b, "\n")
+	}
+	if p.Parent != nil {
+		fmt.Fprintf(b, "## See also\n\n")
+		markdownLink(b, *p.Parent)
+		fmt.Fprintf(b, "\n")
+	}
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+func markdownFlags(w io.Writer, flags []flagDoc) {
+	fmt.Fprintf(w, "| Flag | Type | Default | Description |\n")
+	fmt.Fprintf(w, "|------|------|---------|-------------|\n")
+	for _, f := range flags {
+		def := ""
+		if f.Default != "" {
+			def = "`" + cell(f.Default) + "`"
+		}
+		fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", cell(f.Name), cell(f.Type), def, cell(f.Usage))
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func markdownLink(w io.Writer, l link) {
+	fmt.Fprintf(w, "* [%s](%s.md)", l.Path, l.Name)
+	if l.Description != "" {
+		fmt.Fprintf(w, " - %s", l.Description)
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// cell escapes the characters breaking a table cell
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// GenHTML writes the documentation of the whole command tree
+// as a single HTML file, every command is linked by an anchor
+func GenHTML(w io.Writer, root kli.Command) error {
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Pages []page
+	}{
+		Title: root.Name(),
+		Pages: pages(root),
+	})
+}
+
+func pages(cmd kli.Command) []page {
+	p := []page{newPage(cmd)}
+	for _, child := range cmd.Children() {
+		p = append(p, pages(child)...)
+	}
+	return p
+}
+
+func newPage(cmd kli.Command) page {
+	path := kli.CommandPath(cmd)
+	p := page{
+		Name:        pageName(cmd),
+		Path:        strings.Join(path, " "),
+		Description: cmd.GetDescription(),
+		Detail:      cmd.GetDetail(),
+		Usage:       cmd.Synopsis(),
+		Args:        cmd.GetPositional(),
+		Flags:       flags(cmd),
+	}
+
+	if parent := cmd.Parent(); parent != nil {
+		l := newLink(parent)
+		p.Parent = &l
+
+		root := parent
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+		p.Globals = flags(root)
+	}
+	for _, child := range cmd.Children() {
+		p.Children = append(p.Children, newLink(child))
+	}
+	return p
+}
+
+func newLink(cmd kli.Command) link {
+	return link{
+		Path:        strings.Join(kli.CommandPath(cmd), " "),
+		Name:        pageName(cmd),
+		Description: cmd.GetDescription(),
+	}
+}
+
+// flags returns the documentation of the command flags
+// the type is the kind reported by the command KFlag store
+func flags(cmd kli.Command) []flagDoc {
+	kinds := cmd.GetKFlag().Store()
+	var docs []flagDoc
+	cmd.VisitAll(func(f *flag.Flag) {
+		d := flagDoc{Name: kli.FlagName(cmd, f.Name), Default: f.DefValue, Usage: f.Usage}
+		if choices := cmd.Choices(f.Name); len(choices) > 0 {
+			d.Usage += " (one of: " + strings.Join(choices, ", ") + ")"
+		}
+		if cmd.IsRequired(f.Name) {
+			d.Usage += " (required)"
+		}
+		if k, ok := kinds[f.Name]; ok && k != reflect.Invalid {
+			d.Type = k.String()
+		}
+		docs = append(docs, d)
+	})
+	return docs
+}
+
+// pageName returns the name of the command page, the command path joined by a dash
+func pageName(cmd kli.Command) string {
+	return strings.Join(kli.CommandPath(cmd), "-")
+}
+
+var htmlTemplate = template.Must(template.New("doc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} reference</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: auto; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .3em .6em; text-align: left; }
+pre { background: #f4f4f4; padding: .6em; }
+</style>
+</head>
+<body>
+{{- define "flags"}}
+<table>
+<tr><th>Flag</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{- range .}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{.Usage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .Pages}}
+<section id="{{.Name}}">
+<h1>{{.Path}}</h1>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<h2>Usage</h2>
+<pre>{{.Usage}}</pre>
+{{- if .Detail}}
+<h2>Details</h2>
+<pre>{{.Detail}}</pre>
+{{- end}}
+{{- if .Args}}
+<h2>Arguments</h2>
+<table>
+<tr><th>Argument</th><th>Type</th><th>Description</th></tr>
+{{- range .Args}}
+<tr><td><code>{{.String}}</code></td><td>{{.Type}}</td><td>{{.Usage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Flags}}
+<h2>Flags</h2>
+{{- template "flags" .Flags}}
+{{- end}}
+{{- if .Globals}}
+<h2>Global flags</h2>
+{{- template "flags" .Globals}}
+{{- end}}
+{{- if .Children}}
+<h2>Commands</h2>
+<ul>
+{{- range .Children}}
+<li><a href="#{{.Name}}">{{.Path}}</a>{{if .Description}} - {{.Description}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Parent}}
+<h2>See also</h2>
+<ul>
+<li><a href="#{{.Name}}">{{.Path}}</a>{{if .Description}} - {{.Description}}{{end}}</li>
+</ul>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
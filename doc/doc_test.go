@@ -0,0 +1,108 @@
+package doc_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"github.com/SamuelTissot/kli/doc"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tree(t *testing.T) (kli.Command, kli.Command) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.Description("a smug cow")
+	root.Bool("eat", false, "informs the cow to eat")
+
+	sub := kli.NewCommand("say", flag.ContinueOnError)
+	sub.Description("makes the cow talk")
+	sub.Detail(strings.NewReader("cow say -what moo"))
+	sub.String("what", "mooooo", "what the cow will say")
+	sub.Int("repeat", 1, "how many time it repeats the word")
+
+	err := root.SetChildren(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, sub
+}
+
+func TestGenMarkdown(t *testing.T) {
+	_, sub := tree(t)
+
+	b := &bytes.Buffer{}
+	err := doc.GenMarkdown(b, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustFind := []string{
+		"# cow say\n\nmakes the cow talk",
+		"```\ncow say [flags]\n```",
+		"```\ncow say -what moo\n```",
+		"| `-repeat` | int | `1` | how many time it repeats the word |",
+		"| `-what` | string | `mooooo` | what the cow will say |",
+		"## Global flags\n\n| Flag | Type | Default | Description |\n|------|------|---------|-------------|\n| `-eat` | bool | `false` | informs the cow to eat |",
+		"## See also\n\n* [cow](cow.md) - a smug cow",
+	}
+
+	got := b.String()
+	for _, str := range mustFind {
+		if !strings.Contains(got, str) {
+			t.Errorf("could not find \"%s\" in \n%s", str, got)
+		}
+	}
+}
+
+func TestGenMarkdownTree(t *testing.T) {
+	root, _ := tree(t)
+
+	dir, err := ioutil.TempDir("", "kli-doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = doc.GenMarkdownTree(root, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "cow.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "* [cow say](cow-say.md) - makes the cow talk") {
+		t.Errorf("expected a link to the sub-command in \n%s", b)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cow-say.md")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGenHTML(t *testing.T) {
+	root, _ := tree(t)
+
+	b := &bytes.Buffer{}
+	err := doc.GenHTML(b, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustFind := []string{
+		`<section id="cow">`,
+		`<section id="cow-say">`,
+		`<li><a href="#cow-say">cow say</a> - makes the cow talk</li>`,
+		`<tr><td><code>-what</code></td><td>string</td><td><code>mooooo</code></td><td>what the cow will say</td></tr>`,
+	}
+
+	got := b.String()
+	for _, str := range mustFind {
+		if !strings.Contains(got, str) {
+			t.Errorf("could not find \"%s\" in \n%s", str, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package kli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// enumValue is a string flag.Value restricted to a set of choices
+type enumValue struct {
+	value   *string
+	choices []string
+}
+
+func (e *enumValue) Set(s string) error {
+	for _, c := range e.choices {
+		if s == c {
+			*e.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(e.choices, ", "))
+}
+
+func (e *enumValue) String() string {
+	if e.value == nil {
+		return ""
+	}
+	return *e.value
+}
+
+func (e *enumValue) Get() interface{} {
+	return *e.value
+}
+
+// Enum sets a flag of type string accepting only the choices
+// ex: -format json|yaml|table, it's value is read with KFlag::StringFlag.
+// Enum panics if the default value is not empty nor one of the choices
+func (c *CMD) Enum(name string, value string, choices []string, usage string) {
+	p := new(string)
+	e := &enumValue{value: p, choices: choices}
+	if value != "" {
+		if err := e.Set(value); err != nil {
+			panic(fmt.Sprintf("%s: invalid default value %q for flag -%s: %s", c.Name(), value, name, err.Error()))
+		}
+	}
+	c.FlagSet.Var(e, name, usage)
+	c.KFlag.SetFlag(name, p)
+	def := *p
+	c.setDefault(name, func() { *p = def })
+}
+
+// Choices returns the values accepted by the flag "name"
+// nil if it's not an Enum flag
+func (c *CMD) Choices(name string) []string {
+	f := c.FlagSet.Lookup(name)
+	if f == nil {
+		return nil
+	}
+	if e, ok := f.Value.(*enumValue); ok {
+		return e.choices
+	}
+	return nil
+}
+
+// choicesUsage returns the choices of the flag "name" as shown in the usage
+// an empty string if it's not an Enum flag
+func (c *CMD) choicesUsage(name string) string {
+	if choices := c.Choices(name); len(choices) > 0 {
+		return fmt.Sprintf(" (one of: %s)", strings.Join(choices, ", "))
+	}
+	return ""
+}
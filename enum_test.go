@@ -0,0 +1,53 @@
+package kli_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCMD_Enum(t *testing.T) {
+	cmd := kli.NewCommand("list", flag.ContinueOnError)
+	cmd.Enum("format", "table", []string{"json", "yaml", "table"}, "the output format")
+	cmd.SetOutput(ioutil.Discard)
+
+	if format, ok := cmd.StringFlag("format"); !ok || format != "table" {
+		t.Errorf("expected the default table, got %s", format)
+	}
+	if err := cmd.Parse([]string{"-format", "yaml"}); err != nil {
+		t.Fatal(err)
+	}
+	if format, _ := cmd.StringFlag("format"); format != "yaml" {
+		t.Errorf("expected yaml, got %s", format)
+	}
+	if choices := cmd.Choices("format"); !reflect.DeepEqual(choices, []string{"json", "yaml", "table"}) {
+		t.Errorf("expected the choices, got %v", choices)
+	}
+
+	cmd = kli.NewCommand("list", flag.ContinueOnError)
+	cmd.Enum("format", "table", []string{"json", "yaml", "table"}, "the output format")
+	cmd.SetOutput(ioutil.Discard)
+	err := cmd.Parse([]string{"-format", "xml"})
+	if err == nil || !strings.Contains(err.Error(), "-format") || !strings.Contains(err.Error(), "json, yaml, table") {
+		t.Errorf("expected an error naming the flag and listing the choices, got %v", err)
+	}
+}
+
+func TestApp_Complete_enum(t *testing.T) {
+	root := kli.NewCommand("list", flag.ContinueOnError)
+	root.Enum("format", "", []string{"json", "yaml", "table"}, "the output format")
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	buf := new(bytes.Buffer)
+	if err := app.Complete(buf, []string{"-format", "j"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "json\n" {
+		t.Errorf("expected the choice json, got %q", buf.String())
+	}
+}
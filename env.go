@@ -0,0 +1,102 @@
+package kli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Env binds the flag "name" to the environment variable envVar
+// the variable is used when the flag is not set on the command line
+func (c *CMD) Env(name, envVar string) {
+	if c.env == nil {
+		c.env = make(map[string]string)
+	}
+	c.env[name] = envVar
+}
+
+// SetEnvPrefix binds every flag to the environment variable
+// named by the prefix followed by the upper snake case flag name
+// ex: with the prefix MYTOOL_ the flag dry-run is bound to MYTOOL_DRY_RUN.
+// the explicit bindings, see Env, are not prefixed
+func (c *CMD) SetEnvPrefix(prefix string) {
+	c.envPrefix = prefix
+}
+
+// EnvVar returns the environment variable bound to the flag "name"
+// an empty string if none
+func (c *CMD) EnvVar(name string) string {
+	if envVar, ok := c.env[name]; ok {
+		return envVar
+	}
+	if c.envPrefix == "" {
+		return ""
+	}
+	return envName(c.envPrefix, name)
+}
+
+// SetEnvPrefix sets the environment variable prefix of every command
+// see Command::SetEnvPrefix
+func (a *App) SetEnvPrefix(prefix string) {
+	a.envPrefix = prefix
+}
+
+// setEnvPrefix sets the prefix on the command and it's children
+func setEnvPrefix(cmd Command, prefix string) {
+	cmd.SetEnvPrefix(prefix)
+	for _, child := range cmd.Children() {
+		setEnvPrefix(child, prefix)
+	}
+}
+
+// parseEnv sets the flags not set on the command line
+// from their environment variable, if it's set
+func (c *CMD) parseEnv() error {
+	var err error
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		envVar := c.EnvVar(f.Name)
+		if err != nil || envVar == "" || c.KFlag.Changed(f.Name) {
+			return
+		}
+		value, ok := os.LookupEnv(envVar)
+		if !ok {
+			return
+		}
+		if serr := f.Value.Set(value); serr != nil {
+			err = NewErrorf(MisuseError, "invalid value %q for environment variable %s of flag -%s: %s", value, envVar, f.Name, serr.Error())
+			return
+		}
+		c.KFlag.SetOrigin(f.Name, Origin{Source: SourceEnv, Detail: envVar})
+	})
+	return err
+}
+
+// envName returns the prefix followed by the upper snake case name
+func envName(prefix, name string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	var prev rune
+	for i, r := range name {
+		switch {
+		case r == '-' || r == '.' || r == ' ':
+			b.WriteRune('_')
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(prev):
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+		prev = r
+	}
+	return b.String()
+}
+
+// envUsage returns the environment variable of the flag as shown in the usage
+func (c *CMD) envUsage(name string) string {
+	if envVar := c.EnvVar(name); envVar != "" {
+		return fmt.Sprintf(" [$%s]", envVar)
+	}
+	return ""
+}
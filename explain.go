@@ -0,0 +1,62 @@
+package kli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// ExplainFlag is the hidden flag printing where the flag values come from
+// see App::EnableExplainFlags
+const ExplainFlag = "kli-explain-flags"
+
+// EnableExplainFlags makes the hidden flag --kli-explain-flags print the value
+// and the Origin of the flags of the resolved commands instead of executing it
+func (a *App) EnableExplainFlags() {
+	a.explain = true
+}
+
+// ExplainFlags writes the value and the Origin of the flags
+// of every command on the path of the last execution
+func (a *App) ExplainFlags(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "COMMAND\tFLAG\tVALUE\tSOURCE\tDETAIL")
+	for _, cmd := range a.seen {
+		path := strings.Join(CommandPath(cmd), " ")
+		cmd.VisitAll(func(f *flag.Flag) {
+			origin := cmd.Origin(f.Name)
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", path, f.Name, f.Value.String(), origin.Source, origin.Detail)
+		})
+	}
+	return tw.Flush()
+}
+
+// hasFlag returns true if the flag "name" is in the argument list
+// before the -- terminator
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "-" + name, "--" + name:
+			return true
+		}
+	}
+	return false
+}
+
+// withoutFlag returns the argument list without the flag "name"
+func withoutFlag(args []string, name string) []string {
+	var result []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(result, args[i:]...)
+		}
+		if arg != "-"+name && arg != "--"+name {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
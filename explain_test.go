@@ -0,0 +1,113 @@
+package kli_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_ExplainFlags(t *testing.T) {
+	path := writeConfig(t, "cow.ini", "name = daisy\n[say]\nrepeat = 2\n")
+	defer os.RemoveAll(filepath.Dir(path))
+	defer os.Unsetenv("COW_WHAT")
+	os.Setenv("COW_WHAT", "meuh")
+
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.String("name", "bessie", "the name of the cow")
+	root.Bool("eat", false, "informs the cow to eat")
+
+	origins := map[string]kli.Origin{}
+	say := kli.NewCommand("say", flag.ContinueOnError)
+	say.String("what", "moo", "what the cow will say")
+	say.Int("repeat", 1, "how many time it repeats the word")
+	say.Bool("loud", false, "says it loud")
+	say.Env("what", "COW_WHAT")
+	say.Do(func(cmd kli.Command, globals kli.KFlag) kli.Error {
+		origins["name"] = globals.Origin("name")
+		origins["eat"] = globals.Origin("eat")
+		for _, name := range []string{"what", "repeat", "loud"} {
+			origins[name] = cmd.Origin(name)
+		}
+		return nil
+	})
+	if err := root.SetChildren(say); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.SetConfigFile(path)
+	app.EnableExplainFlags()
+
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"say", "-loud"}))
+	if code != kli.OK || err != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, err)
+	}
+
+	want := map[string]kli.Origin{
+		"name":   {Source: kli.SourceConfig, Detail: path + ": name"},
+		"eat":    {Source: kli.SourceDefault},
+		"what":   {Source: kli.SourceEnv, Detail: "COW_WHAT"},
+		"repeat": {Source: kli.SourceConfig, Detail: path + ": say.repeat"},
+		"loud":   {Source: kli.SourceCommandLine},
+	}
+	for name, origin := range want {
+		if origins[name] != origin {
+			t.Errorf("expected the origin of %s to be %v, got %v", name, origin, origins[name])
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := app.ExplainFlags(buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected a header and 5 flags, got:\n%s", buf.String())
+	}
+	for _, fields := range [][]string{
+		{"cow", "name", "daisy", "config", path + ":", "name"},
+		{"cow say", "what", "meuh", "environment", "COW_WHAT"},
+		{"cow say", "loud", "true", "command line"},
+	} {
+		found := false
+		for _, line := range lines {
+			if strings.Join(strings.Fields(line), " ") == strings.Join(fields, " ") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a line %v, got:\n%s", fields, buf.String())
+		}
+	}
+}
+
+func TestApp_Execute_explainFlags(t *testing.T) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	say := kli.NewCommand("say", flag.ContinueOnError)
+	say.String("what", "moo", "what the cow will say")
+	executed := false
+	say.Do(func(_ kli.Command, _ kli.KFlag) kli.Error {
+		executed = true
+		return nil
+	})
+	if err := root.SetChildren(say); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.EnableExplainFlags()
+
+	code, err := app.Execute(kli.NewContext().SetArgs([]string{"--kli-explain-flags", "say", "-what", "hi"}))
+	if code != kli.OK || err != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, err)
+	}
+	if executed {
+		t.Error("expected the command not to be executed when explaining the flags")
+	}
+}
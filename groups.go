@@ -0,0 +1,88 @@
+package kli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupKind is the constraint applied to a group of flags
+type GroupKind int
+
+const (
+	// ExclusiveGroup allows at most one of the flags to be set
+	ExclusiveGroup GroupKind = iota
+	// TogetherGroup requires all of the flags to be set, or none of them
+	TogetherGroup
+	// OneRequiredGroup requires at least one of the flags to be set
+	OneRequiredGroup
+)
+
+func (k GroupKind) String() string {
+	switch k {
+	case TogetherGroup:
+		return "required together"
+	case OneRequiredGroup:
+		return "at least one required"
+	}
+	return "mutually exclusive"
+}
+
+// FlagGroup is a constraint on a group of flags
+// validated after the flags are parsed
+type FlagGroup struct {
+	Kind  GroupKind
+	Names []string
+}
+
+func (g FlagGroup) String() string {
+	return fmt.Sprintf("%s: %s", dashed(g.Names), g.Kind)
+}
+
+// validate returns an error if the constraint is not met
+func (g FlagGroup) validate(flags KFlag) error {
+	var set []string
+	for _, name := range g.Names {
+		if flags.Changed(name) {
+			set = append(set, name)
+		}
+	}
+
+	switch {
+	case g.Kind == ExclusiveGroup && len(set) > 1:
+		return fmt.Errorf("flags %s are mutually exclusive", dashed(set))
+	case g.Kind == TogetherGroup && len(set) > 0 && len(set) < len(g.Names):
+		return fmt.Errorf("flags %s must be set together, got %s", dashed(g.Names), dashed(set))
+	case g.Kind == OneRequiredGroup && len(set) == 0:
+		return fmt.Errorf("one of the flags %s is required", dashed(g.Names))
+	}
+	return nil
+}
+
+// MutuallyExclusive allows at most one of the flags to be set
+func (c *CMD) MutuallyExclusive(names ...string) {
+	c.groups = append(c.groups, FlagGroup{ExclusiveGroup, names})
+}
+
+// RequiredTogether requires all of the flags to be set, or none of them
+func (c *CMD) RequiredTogether(names ...string) {
+	c.groups = append(c.groups, FlagGroup{TogetherGroup, names})
+}
+
+// OneRequired requires at least one of the flags to be set
+func (c *CMD) OneRequired(names ...string) {
+	c.groups = append(c.groups, FlagGroup{OneRequiredGroup, names})
+}
+
+// FlagGroups returns the command flag groups
+func (c *CMD) FlagGroups() []FlagGroup {
+	return c.groups
+}
+
+// dashed returns the flag names prefixed by a dash, comma separated
+func dashed(names []string) string {
+	d := make([]string, len(names))
+	for i, name := range names {
+		d[i] = "-" + name
+	}
+	return strings.Join(d, ", ")
+}
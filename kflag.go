@@ -1,6 +1,9 @@
 package kli
 
 import (
+	"flag"
+	"net"
+	"net/url"
 	"reflect"
 	"time"
 )
@@ -12,6 +15,32 @@ type KFlag interface {
 	// Set sets a new flag
 	SetFlag(name string, ptr interface{})
 
+	// SetVar sets a new flag holding a custom flag.Value
+	SetVar(name string, value flag.Value)
+
+	// VarFlag return the flag.Value of the flag "name" set with SetVar
+	// use flag.Getter to get it's typed value.
+	// ok is false if the flag does not exist or is not a custom flag.Value
+	VarFlag(name string) (value flag.Value, ok bool)
+
+	// SetChanged marks the flag "name" as set on the command line
+	SetChanged(name string)
+
+	// Changed returns true if the flag "name" was explicitly set
+	// false if it holds it's default value
+	Changed(name string) bool
+
+	// SetOrigin sets where the value of the flag "name" comes from
+	SetOrigin(name string, origin Origin)
+
+	// Origin returns where the value of the flag "name" comes from
+	// the Source is SourceDefault if the flag was not set
+	Origin(name string) Origin
+
+	// ResetOrigins forgets where the values come from
+	// every flag is then reported as not changed
+	ResetOrigins()
+
 	// BoolFlag return the value of the flag "name"
 	// ok is false if the flag does not exist or of wrong type
 	BoolFlag(name string) (value, ok bool)
@@ -43,28 +72,152 @@ type KFlag interface {
 	// Uint64Flag return the value of the flag "name"
 	// ok is false if the flag does not exist or of wrong type
 	Uint64Flag(name string) (value uint64, ok bool)
+
+	// StringSliceFlag return the values of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	StringSliceFlag(name string) (value []string, ok bool)
+
+	// IntSliceFlag return the values of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	IntSliceFlag(name string) (value []int, ok bool)
+
+	// DurationSliceFlag return the values of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	DurationSliceFlag(name string) (value []time.Duration, ok bool)
+
+	// StringMapFlag return the key value pairs of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	StringMapFlag(name string) (value map[string]string, ok bool)
+
+	// BytesFlag return the value of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	BytesFlag(name string) (value ByteSize, ok bool)
+
+	// TimeFlag return the value of the Time or Date flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	TimeFlag(name string) (value time.Time, ok bool)
+
+	// URLFlag return the value of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	URLFlag(name string) (value *url.URL, ok bool)
+
+	// IPFlag return the value of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	IPFlag(name string) (value net.IP, ok bool)
+
+	// IPNetFlag return the value of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	IPNetFlag(name string) (value *net.IPNet, ok bool)
+
+	// PathFlag return the value of the flag "name"
+	// ok is false if the flag does not exist or of wrong type
+	PathFlag(name string) (value string, ok bool)
+}
+
+// Source is where the value of a flag comes from
+type Source int
+
+const (
+	SourceDefault Source = iota
+	SourceCommandLine
+	SourceEnv
+	SourceConfig
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceCommandLine:
+		return "command line"
+	case SourceEnv:
+		return "environment"
+	case SourceConfig:
+		return "config"
+	}
+	return "default"
+}
+
+// Origin describes where the value of a flag comes from
+type Origin struct {
+	Source Source
+	// Detail is the environment variable, or the configuration file and key,
+	// the value was read from
+	Detail string
 }
 
 type FlagStore struct {
-	f map[string]interface{}
+	f       map[string]interface{}
+	vars    map[string]flag.Value
+	origins map[string]Origin
 }
 
 func NewKflag() *FlagStore {
-	return &FlagStore{map[string]interface{}{}}
+	return &FlagStore{
+		f:       map[string]interface{}{},
+		vars:    map[string]flag.Value{},
+		origins: map[string]Origin{},
+	}
 }
 
+// Store returns the kind of the flags, for a custom flag.Value
+// it's the kind of the value returned by flag.Getter, if implemented,
+// or the kind of the type implementing flag.Value
 func (a *FlagStore) Store() map[string]reflect.Kind {
 	result := make(map[string]reflect.Kind)
 	for name, f := range a.f {
 		result[name] = reflect.TypeOf(f).Elem().Kind()
 	}
+	for name, v := range a.vars {
+		result[name] = varKind(v)
+	}
 	return result
 }
 
+func varKind(v flag.Value) reflect.Kind {
+	if g, ok := v.(flag.Getter); ok {
+		if k := reflect.ValueOf(g.Get()).Kind(); k != reflect.Invalid {
+			return k
+		}
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind()
+}
+
 func (a *FlagStore) SetFlag(name string, ptr interface{}) {
 	a.f[name] = ptr
 }
 
+func (a *FlagStore) SetVar(name string, value flag.Value) {
+	a.vars[name] = value
+}
+
+func (a *FlagStore) VarFlag(name string) (value flag.Value, ok bool) {
+	value, ok = a.vars[name]
+	return
+}
+
+func (a *FlagStore) SetChanged(name string) {
+	a.SetOrigin(name, Origin{Source: SourceCommandLine})
+}
+
+func (a *FlagStore) Changed(name string) bool {
+	return a.Origin(name).Source != SourceDefault
+}
+
+func (a *FlagStore) SetOrigin(name string, origin Origin) {
+	a.origins[name] = origin
+}
+
+func (a *FlagStore) Origin(name string) Origin {
+	return a.origins[name]
+}
+
+func (a *FlagStore) ResetOrigins() {
+	a.origins = map[string]Origin{}
+}
+
 // flagElem returns the FlagStore for the given name
 func (a *FlagStore) flagElem(name string) reflect.Value {
 	if f, ok := a.f[name]; ok {
@@ -83,17 +236,8 @@ func (a *FlagStore) BoolFlag(name string) (value, ok bool) {
 }
 
 func (a *FlagStore) DurationFlag(name string) (value time.Duration, ok bool) {
-	f := a.flagElem(name)
-	if f.Kind() != reflect.String {
-		return
-	}
-
-	d, e := time.ParseDuration(f.String())
-	if e != nil {
-		return
-	}
-
-	return d, true
+	value, ok = a.typedElem(name).(time.Duration)
+	return
 }
 
 func (a *FlagStore) Float64Flag(name string) (value float64, ok bool) {
@@ -113,11 +257,8 @@ func (a *FlagStore) IntFlag(name string) (value int, ok bool) {
 }
 
 func (a *FlagStore) Int64Flag(name string) (value int64, ok bool) {
-	f := a.flagElem(name)
-	if f.Kind() != reflect.Int64 {
-		return
-	}
-	return f.Int(), true
+	value, ok = a.typedElem(name).(int64)
+	return
 }
 
 func (a *FlagStore) StringFlag(name string) (value string, ok bool) {
@@ -144,3 +285,78 @@ func (a *FlagStore) Uint64Flag(name string) (value uint64, ok bool) {
 	}
 	return f.Uint(), true
 }
+
+func (a *FlagStore) StringSliceFlag(name string) (value []string, ok bool) {
+	f := a.flagElem(name)
+	if f.Kind() != reflect.Slice {
+		return
+	}
+	value, ok = f.Interface().([]string)
+	return
+}
+
+func (a *FlagStore) IntSliceFlag(name string) (value []int, ok bool) {
+	f := a.flagElem(name)
+	if f.Kind() != reflect.Slice {
+		return
+	}
+	value, ok = f.Interface().([]int)
+	return
+}
+
+func (a *FlagStore) DurationSliceFlag(name string) (value []time.Duration, ok bool) {
+	f := a.flagElem(name)
+	if f.Kind() != reflect.Slice {
+		return
+	}
+	value, ok = f.Interface().([]time.Duration)
+	return
+}
+
+func (a *FlagStore) StringMapFlag(name string) (value map[string]string, ok bool) {
+	f := a.flagElem(name)
+	if f.Kind() != reflect.Map {
+		return
+	}
+	value, ok = f.Interface().(map[string]string)
+	return
+}
+
+// typedElem returns the value of the flag "name" if it's valid
+func (a *FlagStore) typedElem(name string) interface{} {
+	f := a.flagElem(name)
+	if !f.IsValid() {
+		return nil
+	}
+	return f.Interface()
+}
+
+func (a *FlagStore) BytesFlag(name string) (value ByteSize, ok bool) {
+	value, ok = a.typedElem(name).(ByteSize)
+	return
+}
+
+func (a *FlagStore) TimeFlag(name string) (value time.Time, ok bool) {
+	value, ok = a.typedElem(name).(time.Time)
+	return
+}
+
+func (a *FlagStore) URLFlag(name string) (value *url.URL, ok bool) {
+	value, ok = a.typedElem(name).(*url.URL)
+	return
+}
+
+func (a *FlagStore) IPFlag(name string) (value net.IP, ok bool) {
+	value, ok = a.typedElem(name).(net.IP)
+	return
+}
+
+func (a *FlagStore) IPNetFlag(name string) (value *net.IPNet, ok bool) {
+	value, ok = a.typedElem(name).(*net.IPNet)
+	return
+}
+
+func (a *FlagStore) PathFlag(name string) (value string, ok bool) {
+	p, ok := a.typedElem(name).(pathString)
+	return string(p), ok
+}
@@ -0,0 +1,212 @@
+// Package man generates roff man pages from a kli command tree
+package man
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/SamuelTissot/kli"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Header is the title line of the man pages
+type Header struct {
+	// Section is the manual section, defaults to 1 (user commands)
+	Section string
+	// Date is the date of the page, defaults to now
+	Date time.Time
+	// Source is the source of the command, usually the project name and version
+	Source string
+	// Manual is the title of the manual
+	Manual string
+}
+
+// GenTree writes one man page per command to the directory dir
+// starting with the root command and walking it's children.
+// the page of the command `cow say` is named cow-say.1
+func GenTree(root kli.Command, dir string, header *Header) error {
+	h := defaults(header)
+	return genTree(root, dir, h)
+}
+
+func genTree(cmd kli.Command, dir string, header *Header) error {
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("%s.%s", pageName(cmd), header.Section)))
+	if err != nil {
+		return err
+	}
+
+	err = Gen(f, cmd, header)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, child := range cmd.Children() {
+		if err := genTree(child, dir, header); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Gen writes the man page of the command to w
+func Gen(w io.Writer, cmd kli.Command, header *Header) error {
+	h := defaults(header)
+	path := kli.CommandPath(cmd)
+	b := &bytes.Buffer{}
+
+	fmt.Fprintf(b, ".TH %s %s %s %s %s\n",
+		quote(strings.ToUpper(pageName(cmd))), quote(h.Section), quote(h.Date.Format("Jan 2006")), quote(h.Source), quote(h.Manual))
+
+	fmt.Fprintf(b, ".SH NAME\n")
+	if desc := cmd.GetDescription(); desc != "" {
+		fmt.Fprintf(b, "%s \\- %s\n", escape(strings.Join(path, "-")), escape(desc))
+	} else {
+		fmt.Fprintf(b, "%s\n", escape(strings.Join(path, "-")))
+	}
+
+	fmt.Fprintf(b, ".SH SYNOPSIS\n")
+	fmt.Fprintf(b, "\\fB%s\\fR", escape(strings.Join(path, " ")))
+	if hasFlags(cmd) {
+		fmt.Fprintf(b, " [\\fIflags\\fR]")
+	}
+	if len(cmd.Children()) > 0 {
+		fmt.Fprintf(b, " [\\fIcommand\\fR]")
+	}
+	for _, arg := range cmd.GetPositional() {
+		fmt.Fprintf(b, " \\fI%s\\fR", escape(arg.String()))
+	}
+	fmt.Fprintf(b, "\n")
+
+	if desc, detail := cmd.GetDescription(), cmd.GetDetail(); desc != "" || detail != "" {
+		fmt.Fprintf(b, ".SH DESCRIPTION\n")
+		if desc != "" {
+			fmt.Fprintf(b, "%s\n", escape(desc))
+		}
+		if detail != "" {
+			fmt.Fprintf(b, ".PP\n.nf\n%s\n.fi\n", escape(strings.TrimRight(detail, "\n")))
+		}
+	}
+
+	if args := cmd.GetPositional(); len(args) > 0 {
+		fmt.Fprintf(b, ".SH ARGUMENTS\n")
+		for _, arg := range args {
+			fmt.Fprintf(b, ".TP\n\\fI%s\\fR %s\n%s\n", escape(arg.String()), escape(arg.Type.String()), escape(arg.Usage))
+		}
+	}
+
+	if hasFlags(cmd) {
+		fmt.Fprintf(b, ".SH OPTIONS\n")
+		writeFlags(b, cmd)
+	}
+
+	if root := rootOf(cmd); root != cmd && hasFlags(root) {
+		fmt.Fprintf(b, ".SH GLOBAL OPTIONS\n")
+		writeFlags(b, root)
+	}
+
+	if len(cmd.Children()) > 0 {
+		fmt.Fprintf(b, ".SH COMMANDS\n")
+		for _, child := range cmd.Children() {
+			fmt.Fprintf(b, ".TP\n\\fB%s\\fR\n%s\n", escape(child.Name()), escape(child.GetDescription()))
+		}
+	}
+
+	var related []string
+	if parent := cmd.Parent(); parent != nil {
+		related = append(related, seeAlso(parent, h))
+	}
+	for _, child := range cmd.Children() {
+		related = append(related, seeAlso(child, h))
+	}
+	if len(related) > 0 {
+		fmt.Fprintf(b, ".SH SEE ALSO\n%s\n", strings.Join(related, ", "))
+	}
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// writeFlags writes the command flags as tagged paragraphs
+func writeFlags(w io.Writer, cmd kli.Command) {
+	cmd.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(w, ".TP\n\\fB%s\\fR", escape(kli.FlagName(cmd, f.Name)))
+		if name != "" {
+			fmt.Fprintf(w, " \\fI%s\\fR", escape(name))
+		}
+		fmt.Fprintf(w, "\n%s", escape(usage))
+		if f.DefValue != "" {
+			fmt.Fprintf(w, " (default: %s)", escape(f.DefValue))
+		}
+		if choices := cmd.Choices(f.Name); len(choices) > 0 {
+			fmt.Fprintf(w, " (one of: %s)", escape(strings.Join(choices, ", ")))
+		}
+		if cmd.IsRequired(f.Name) {
+			fmt.Fprintf(w, " (required)")
+		}
+		fmt.Fprintf(w, "\n")
+	})
+}
+
+func hasFlags(cmd kli.Command) bool {
+	has := false
+	cmd.VisitAll(func(*flag.Flag) {
+		has = true
+	})
+	return has
+}
+
+func rootOf(cmd kli.Command) kli.Command {
+	for cmd.Parent() != nil {
+		cmd = cmd.Parent()
+	}
+	return cmd
+}
+
+// pageName returns the name of the command page, the command path joined by a dash
+func pageName(cmd kli.Command) string {
+	return strings.Join(kli.CommandPath(cmd), "-")
+}
+
+func seeAlso(cmd kli.Command, header *Header) string {
+	return fmt.Sprintf("\\fB%s\\fR(%s)", escape(pageName(cmd)), header.Section)
+}
+
+func defaults(header *Header) *Header {
+	h := Header{}
+	if header != nil {
+		h = *header
+	}
+	if h.Section == "" {
+		h.Section = "1"
+	}
+	if h.Date.IsZero() {
+		h.Date = time.Now()
+	}
+	return &h
+}
+
+// quote quotes a macro argument
+func quote(s string) string {
+	return `"` + strings.Replace(escape(s), `"`, `\(dq`, -1) + `"`
+}
+
+// escape escapes the roff special characters
+// and the control characters at the beginning of a line
+func escape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+package man_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"github.com/SamuelTissot/kli/man"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tree(t *testing.T) (kli.Command, kli.Command) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.Description("a smug cow")
+	root.Bool("eat", false, "informs the cow to eat")
+
+	sub := kli.NewCommand("say", flag.ContinueOnError)
+	sub.Description("makes the cow talk")
+	sub.Detail(strings.NewReader("cow say -what moo\n.starts with a dot"))
+	sub.String("what", "mooooo", "what the cow will say")
+
+	err := root.SetChildren(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root, sub
+}
+
+func TestGen(t *testing.T) {
+	_, sub := tree(t)
+
+	b := &bytes.Buffer{}
+	err := man.Gen(b, sub, &man.Header{Date: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), Source: "cow 1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustFind := []string{
+		`.TH "COW\-SAY" "1" "Jun 2019" "cow 1.0" ""`,
+		`cow\-say \- makes the cow talk`,
+		`\fBcow say\fR [\fIflags\fR]`,
+		`\&.starts with a dot`,
+		".SH OPTIONS\n.TP\n\\fB\\-what\\fR \\fIstring\\fR\nwhat the cow will say (default: mooooo)",
+		".SH GLOBAL OPTIONS\n.TP\n\\fB\\-eat\\fR\ninforms the cow to eat (default: false)",
+		".SH SEE ALSO\n\\fBcow\\fR(1)",
+	}
+
+	got := b.String()
+	for _, str := range mustFind {
+		if !strings.Contains(got, str) {
+			t.Errorf("could not find \"%s\" in \n%s", str, got)
+		}
+	}
+}
+
+func TestGenTree(t *testing.T) {
+	root, _ := tree(t)
+
+	dir, err := ioutil.TempDir("", "kli-man")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = man.GenTree(root, dir, &man.Header{Section: "8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cow.8", "cow-say.8"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected the page %s: %s", name, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package kli
+
+import "fmt"
+
+// Negatable allows the boolean flags to be turned off with --no-name
+// ex: with cmd.Negatable("color") --no-color sets color to false.
+// Negatable panics if a flag is not a defined boolean flag
+func (c *CMD) Negatable(names ...string) {
+	for _, name := range names {
+		f := c.FlagSet.Lookup(name)
+		if f == nil || !isBoolFlag(f) {
+			panic(fmt.Sprintf("%s: -%s is not a boolean flag, it can not be negated", c.Name(), name))
+		}
+		c.negatable = append(c.negatable, name)
+	}
+}
+
+// IsNegatable returns true if the flag "name" can be turned off with --no-name
+func (c *CMD) IsNegatable(name string) bool {
+	for _, n := range c.negatable {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
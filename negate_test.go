@@ -0,0 +1,88 @@
+package kli_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"testing"
+)
+
+func TestCMD_Parse_negatable(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		color  bool
+		origin kli.Origin
+	}{
+		{"default", []string{}, true, kli.Origin{}},
+		{"negated", []string{"--no-color"}, false, kli.Origin{Source: kli.SourceCommandLine, Detail: "--no-color"}},
+		{"single dash", []string{"-no-color"}, false, kli.Origin{Source: kli.SourceCommandLine, Detail: "--no-color"}},
+		{"set", []string{"--color"}, true, kli.Origin{Source: kli.SourceCommandLine}},
+		{"bundled with a short flag", []string{"-v", "--no-color"}, false, kli.Origin{Source: kli.SourceCommandLine, Detail: "--no-color"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := kli.NewCommand("ls", flag.ContinueOnError)
+			cmd.Bool("color", true, "colors the output")
+			cmd.Bool("verbose", false, "lists the details")
+			cmd.Short("verbose", 'v')
+			cmd.Negatable("color")
+
+			if err := cmd.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if color, _ := cmd.BoolFlag("color"); color != tt.color {
+				t.Errorf("expected color to be %v, got %v", tt.color, color)
+			}
+			if origin := cmd.Origin("color"); origin != tt.origin {
+				t.Errorf("expected the origin %v, got %v", tt.origin, origin)
+			}
+		})
+	}
+}
+
+func TestCMD_Parse_notNegatable(t *testing.T) {
+	cmd := kli.NewCommand("ls", flag.ContinueOnError)
+	cmd.Bool("color", true, "colors the output")
+	cmd.SetOutput(ioutil.Discard)
+
+	if err := cmd.Parse([]string{"--no-color"}); err == nil {
+		t.Error("expected an error for the negated form of a flag that is not negatable")
+	}
+}
+
+func TestApp_Complete_negatable(t *testing.T) {
+	root := kli.NewCommand("ls", flag.ContinueOnError)
+	root.Bool("color", true, "colors the output")
+	root.Negatable("color")
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	buf := new(bytes.Buffer)
+	if err := app.Complete(buf, []string{"--no"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "--no-color\tcolors the output\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestFlagName(t *testing.T) {
+	cmd := kli.NewCommand("ls", flag.ContinueOnError)
+	cmd.Bool("color", true, "colors the output")
+	cmd.Bool("all", false, "lists all")
+	cmd.Bool("verbose", false, "lists the details")
+	cmd.Negatable("color", "all")
+	cmd.Short("all", 'a')
+
+	for name, want := range map[string]string{
+		"color":   "--[no-]color",
+		"all":     "-a, --[no-]all",
+		"verbose": "-verbose",
+	} {
+		if got := kli.FlagName(cmd, name); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package kli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Short sets the one letter short name of the flag "name"
+// ex: with cmd.Short("verbose", 'v') -v and --verbose set the same flag.
+// the short boolean flags can be bundled, -xvf file, and the value of
+// a short flag can be attached, -ffile or -f=file.
+// Short panics if the flag is not defined or the letter is already used
+func (c *CMD) Short(name string, short rune) {
+	if c.FlagSet.Lookup(name) == nil {
+		panic(fmt.Sprintf("%s: short name -%c for undefined flag -%s", c.Name(), short, name))
+	}
+	if _, ok := c.shorts[short]; ok || c.FlagSet.Lookup(string(short)) != nil {
+		panic(fmt.Sprintf("%s flag redefined: -%c", c.Name(), short))
+	}
+	if c.shorts == nil {
+		c.shorts = make(map[rune]string)
+	}
+	c.shorts[short] = name
+}
+
+// GetShort returns the short name of the flag "name", 0 if none
+func (c *CMD) GetShort(name string) rune {
+	for short, long := range c.shorts {
+		if long == name {
+			return short
+		}
+	}
+	return 0
+}
+
+// LookupShort returns the flag with the short name, nil if none
+func (c *CMD) LookupShort(short rune) *flag.Flag {
+	name, ok := c.shorts[short]
+	if !ok {
+		return nil
+	}
+	return c.FlagSet.Lookup(name)
+}
+
+// FlagName returns the flag as shown in the usage
+// -v, --verbose for a flag with a short name, -verbose otherwise.
+// the negatable flags are shown as --[no-]color
+func FlagName(cmd Command, name string) string {
+	long := name
+	if cmd.IsNegatable(name) {
+		long = "[no-]" + name
+	}
+	if short := cmd.GetShort(name); short != 0 {
+		return fmt.Sprintf("-%c, --%s", short, long)
+	}
+	if long != name {
+		return "--" + long
+	}
+	return "-" + name
+}
+
+// expandArgs rewrites the short flags of the argument list with their long name
+// -xvf file becomes --extract --verbose --file file, and the negated flags
+// with their value, --no-color becomes --color=false. The arguments following
+// the first non flag argument, or the -- terminator, are left as is
+func (c *CMD) expandArgs(args []string) ([]string, error) {
+	if len(c.shorts) == 0 && len(c.negatable) == 0 {
+		return args, nil
+	}
+
+	var result []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			return append(result, args[i:]...), nil
+		}
+
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if negated := strings.TrimPrefix(name, "no-"); negated != name && name == strings.TrimLeft(arg, "-") &&
+			c.FlagSet.Lookup(name) == nil && c.IsNegatable(negated) {
+			c.negated = append(c.negated, negated)
+			result = append(result, "--"+negated+"=false")
+			continue
+		}
+		first := []rune(arg[1:])[0]
+		if f := c.FlagSet.Lookup(name); f != nil || strings.HasPrefix(arg, "--") || c.LookupShort(first) == nil {
+			// a long flag, or an undefined one reported by the FlagSet
+			result = append(result, arg)
+			if f != nil && !isBoolFlag(f) && !strings.Contains(arg, "=") && i+1 < len(args) {
+				i++
+				result = append(result, args[i])
+			}
+			continue
+		}
+
+		letters := []rune(arg[1:])
+		for j, short := range letters {
+			f := c.LookupShort(short)
+			if f == nil {
+				return nil, NewErrorf(NotFount, "unknown shorthand flag %q in %s for %s", string(short), arg, c.Name())
+			}
+			rest := string(letters[j+1:])
+			if isBoolFlag(f) && !strings.HasPrefix(rest, "=") {
+				result = append(result, "--"+f.Name)
+				continue
+			}
+
+			if rest != "" {
+				result = append(result, "--"+f.Name+"="+strings.TrimPrefix(rest, "="))
+				break
+			}
+			// the value is the next argument
+			result = append(result, "--"+f.Name)
+			if i+1 < len(args) {
+				i++
+				result = append(result, args[i])
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package kli_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func shortCommand() *kli.CMD {
+	cmd := kli.NewCommand("tar", flag.ContinueOnError)
+	cmd.Bool("extract", false, "extracts the archive")
+	cmd.Bool("verbose", false, "lists the files")
+	cmd.String("file", "", "the archive")
+	cmd.Int("level", 0, "the compression level")
+	cmd.Short("extract", 'x')
+	cmd.Short("verbose", 'v')
+	cmd.Short("file", 'f')
+	cmd.Short("level", 'l')
+	cmd.SetOutput(ioutil.Discard)
+	return cmd
+}
+
+func TestCMD_Parse_short(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		extract bool
+		verbose bool
+		file    string
+		level   int
+		rest    []string
+	}{
+		{"long", []string{"--verbose", "--file", "a.tar", "b"}, false, true, "a.tar", 0, []string{"b"}},
+		{"single dash long", []string{"-verbose", "-file=a.tar"}, false, true, "a.tar", 0, nil},
+		{"short", []string{"-v", "-f", "a.tar"}, false, true, "a.tar", 0, nil},
+		{"bundle", []string{"-xvf", "a.tar", "b"}, true, true, "a.tar", 0, []string{"b"}},
+		{"attached value", []string{"-fa.tar", "-l9"}, false, false, "a.tar", 9, nil},
+		{"equal value", []string{"-f=a.tar", "-v=false"}, false, false, "a.tar", 0, nil},
+		{"bundle attached value", []string{"-xl3"}, true, false, "", 3, nil},
+		{"stops at the first argument", []string{"b", "-v"}, false, false, "", 0, []string{"b", "-v"}},
+		{"terminator", []string{"-x", "--", "-v"}, true, false, "", 0, []string{"-v"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := shortCommand()
+			if err := cmd.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			extract, _ := cmd.BoolFlag("extract")
+			verbose, _ := cmd.BoolFlag("verbose")
+			file, _ := cmd.StringFlag("file")
+			level, _ := cmd.IntFlag("level")
+			if extract != tt.extract || verbose != tt.verbose || file != tt.file || level != tt.level {
+				t.Errorf("expected extract=%v verbose=%v file=%q level=%d, got extract=%v verbose=%v file=%q level=%d",
+					tt.extract, tt.verbose, tt.file, tt.level, extract, verbose, file, level)
+			}
+			if len(tt.rest) > 0 && !reflect.DeepEqual(cmd.Args(), tt.rest) {
+				t.Errorf("expected the arguments %v, got %v", tt.rest, cmd.Args())
+			}
+		})
+	}
+}
+
+func TestCMD_Parse_unknownShort(t *testing.T) {
+	cmd := shortCommand()
+	err := cmd.Parse([]string{"-xqf", "a.tar"})
+	kerr, ok := err.(kli.Error)
+	if !ok || kerr.Code() != kli.NotFount || !strings.Contains(err.Error(), `"q"`) {
+		t.Errorf("expected a NotFount error naming the shorthand q, got %v", err)
+	}
+}
+
+func TestCMD_Short_redefined(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Short to panic when the letter is already used")
+		}
+	}()
+	cmd := shortCommand()
+	cmd.Bool("vacuum", false, "vacuums")
+	cmd.Short("vacuum", 'v')
+}
+
+func TestApp_Complete_short(t *testing.T) {
+	root := shortCommand()
+	root.CompleteFlag("file", func(kli.Command, []string, string) []string {
+		return []string{"a.tar", "b.tar"}
+	})
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-xf", ""}, "a.tar\nb.tar\n"},
+		{[]string{"-f=a"}, "-f=a.tar\n"},
+		{[]string{"--verb"}, "--verbose\tlists the files\n"},
+	}
+	for _, tt := range tests {
+		buf := new(bytes.Buffer)
+		if err := app.Complete(buf, tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%v: expected %q, got %q", tt.args, tt.want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package kli
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// DefaultGracePeriod is the time given to the executed command
+// to clean up after it's context was cancelled by a signal
+const DefaultGracePeriod = 5 * time.Second
+
+// terminationSignals are the signals that cancel the execution context
+var terminationSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// SetGracePeriod sets the time given to the executed command to return
+// once it's context was cancelled by SIGINT or SIGTERM.
+// once elapsed the app exits. A negative duration waits for the command to return
+func (a *App) SetGracePeriod(d time.Duration) {
+	a.grace = d
+	a.graceSet = true
+}
+
+func (a *App) gracePeriod() time.Duration {
+	if !a.graceSet {
+		return DefaultGracePeriod
+	}
+	return a.grace
+}
+
+// executeWithSignals executes the app and cancels the context
+// on the first signal received. The command then has the grace period to return
+// a second signal, or the end of the grace period, stops the waiting.
+// when interrupted the exit code is 128 + the signal number
+func (a *App) executeWithSignals(ctx *Context, sigs <-chan os.Signal) (int, Error) {
+	cancel := ctx.WithCancel()
+	defer cancel()
+
+	type result struct {
+		code int
+		err  Error
+	}
+	done := make(chan result, 1)
+	go func() {
+		code, err := a.Execute(ctx)
+		done <- result{code, err}
+	}()
+
+	var sig os.Signal
+	select {
+	case r := <-done:
+		return r.code, r.err
+	case sig = <-sigs:
+		cancel()
+	}
+
+	code := signalCode(sig)
+	var timeout <-chan time.Time
+	if g := a.gracePeriod(); g >= 0 {
+		timeout = time.After(g)
+	}
+
+	select {
+	case <-done:
+		return code, NewErrorf(code, "terminated by signal: %s", sig)
+	case sig = <-sigs:
+		return code, NewErrorf(code, "forced termination by signal: %s", sig)
+	case <-timeout:
+		return code, NewErrorf(code, "terminated by signal: %s, grace period of %s exceeded", sig, a.gracePeriod())
+	}
+}
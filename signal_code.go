@@ -0,0 +1,17 @@
+//go:build !plan9
+// +build !plan9
+
+package kli
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalCode returns the exit code for the signal, 128 + the signal number
+func signalCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return UserTermination
+}
@@ -0,0 +1,9 @@
+package kli
+
+import "os"
+
+// signalCode returns the exit code for the signal
+// plan9 notes have no number, it's always UserTermination
+func signalCode(_ os.Signal) int {
+	return UserTermination
+}
@@ -0,0 +1,161 @@
+package kli
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"reflect"
+)
+
+// SpecVersion is the version of the Spec schema
+// it changes when a field is removed or it's meaning changes
+const SpecVersion = "1"
+
+// DumpSpecFlag is the hidden flag printing the app Spec as JSON
+// see App::EnableSpecDump
+const DumpSpecFlag = "kli-dump-spec"
+
+// Spec is the machine readable description of the app
+type Spec struct {
+	Version string      `json:"version"`
+	Root    CommandSpec `json:"root"`
+}
+
+// CommandSpec describes a command, it's flags and it's children
+type CommandSpec struct {
+	Name        string        `json:"name"`
+	Aliases     []string      `json:"aliases"`
+	Path        []string      `json:"path"`
+	Description string        `json:"description"`
+	Detail      string        `json:"detail"`
+	Executable  bool          `json:"executable"`
+	Args        []ArgSpec     `json:"args"`
+	Flags       []FlagSpec    `json:"flags"`
+	FlagGroups  []GroupSpec   `json:"flag_groups"`
+	Children    []CommandSpec `json:"children"`
+}
+
+// ArgSpec describes a positional argument
+type ArgSpec struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Usage    string `json:"usage"`
+	Optional bool   `json:"optional"`
+	Variadic bool   `json:"variadic"`
+}
+
+// GroupSpec describes a flag group
+// the kind is one of: exclusive, together, one_required
+type GroupSpec struct {
+	Kind  string   `json:"kind"`
+	Flags []string `json:"flags"`
+}
+
+// FlagSpec describes a flag
+// the kind is the reflect.Kind reported by the command KFlag store
+type FlagSpec struct {
+	Name     string `json:"name"`
+	Short    string `json:"short,omitempty"`
+	Kind     string `json:"kind"`
+	Default  string `json:"default"`
+	Usage    string `json:"usage"`
+	Required bool   `json:"required"`
+	Env      string `json:"env"`
+	// Negatable is true if the flag can be turned off with --no-name
+	Negatable bool `json:"negatable,omitempty"`
+	// Choices are the values accepted by an Enum flag
+	Choices []string `json:"choices,omitempty"`
+}
+
+var groupKinds = map[GroupKind]string{
+	ExclusiveGroup:   "exclusive",
+	TogetherGroup:    "together",
+	OneRequiredGroup: "one_required",
+}
+
+// Spec returns the description of the app command tree
+func (a *App) Spec() Spec {
+	return Spec{
+		Version: SpecVersion,
+		Root:    NewCommandSpec(a.root),
+	}
+}
+
+// EnableSpecDump makes the hidden flag --kli-dump-spec print
+// the app Spec as JSON instead of executing the command
+func (a *App) EnableSpecDump() {
+	a.specDump = true
+}
+
+// WriteSpec writes the app Spec as indented JSON
+func (a *App) WriteSpec(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a.Spec())
+}
+
+// NewCommandSpec returns the description of the command and it's children
+func NewCommandSpec(cmd Command) CommandSpec {
+	spec := CommandSpec{
+		Name:        cmd.Name(),
+		Aliases:     append([]string{}, cmd.GetAliases()...),
+		Path:        CommandPath(cmd),
+		Description: cmd.GetDescription(),
+		Detail:      cmd.GetDetail(),
+		Executable:  cmd.IsExecutable(),
+		Args:        []ArgSpec{},
+		Flags:       []FlagSpec{},
+		FlagGroups:  []GroupSpec{},
+		Children:    []CommandSpec{},
+	}
+
+	for _, arg := range cmd.GetPositional() {
+		spec.Args = append(spec.Args, ArgSpec{
+			Name:     arg.Name,
+			Type:     arg.Type.String(),
+			Usage:    arg.Usage,
+			Optional: arg.Optional,
+			Variadic: arg.Variadic,
+		})
+	}
+
+	kinds := cmd.GetKFlag().Store()
+	cmd.VisitAll(func(f *flag.Flag) {
+		kind, ok := kinds[f.Name]
+		if !ok {
+			kind = reflect.Invalid
+		}
+		short := ""
+		if r := cmd.GetShort(f.Name); r != 0 {
+			short = string(r)
+		}
+		spec.Flags = append(spec.Flags, FlagSpec{
+			Name:      f.Name,
+			Short:     short,
+			Kind:      kind.String(),
+			Default:   f.DefValue,
+			Usage:     f.Usage,
+			Required:  cmd.IsRequired(f.Name),
+			Env:       cmd.EnvVar(f.Name),
+			Negatable: cmd.IsNegatable(f.Name),
+			Choices:   cmd.Choices(f.Name),
+		})
+	})
+
+	for _, g := range cmd.FlagGroups() {
+		spec.FlagGroups = append(spec.FlagGroups, GroupSpec{
+			Kind:  groupKinds[g.Kind],
+			Flags: append([]string{}, g.Names...),
+		})
+	}
+
+	for _, child := range cmd.Children() {
+		spec.Children = append(spec.Children, NewCommandSpec(child))
+	}
+	return spec
+}
+
+// wantsSpec returns true if the arguments contains the DumpSpecFlag
+func wantsSpec(args []string) bool {
+	return hasFlag(args, DumpSpecFlag)
+}
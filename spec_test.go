@@ -0,0 +1,135 @@
+package kli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestApp_Spec(t *testing.T) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	root.Description("a smug cow")
+	root.Bool("eat", false, "informs the cow to eat")
+
+	sub := kli.NewCommand("say", flag.ContinueOnError)
+	sub.Description("makes the cow talk")
+	sub.Int("repeat", 1, "how many time it repeats the word")
+	sub.Do(func(kli.Command, kli.KFlag) kli.Error {
+		return nil
+	})
+
+	err := root.SetChildren(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+
+	b := &bytes.Buffer{}
+	err = app.WriteSpec(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spec kli.Spec
+	err = json.Unmarshal(b.Bytes(), &spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Version != kli.SpecVersion {
+		t.Errorf("expected the version %s, got %s", kli.SpecVersion, spec.Version)
+	}
+	if spec.Root.Name != "cow" || spec.Root.Executable || len(spec.Root.Children) != 1 {
+		t.Fatalf("unexpected root spec %+v", spec.Root)
+	}
+
+	want := kli.FlagSpec{Name: "eat", Kind: "bool", Default: "false", Usage: "informs the cow to eat"}
+	if len(spec.Root.Flags) != 1 || !reflect.DeepEqual(spec.Root.Flags[0], want) {
+		t.Errorf("expected the flags [%+v], got %+v", want, spec.Root.Flags)
+	}
+
+	say := spec.Root.Children[0]
+	if len(say.Path) != 2 || say.Path[0] != "cow" || say.Path[1] != "say" {
+		t.Errorf("expected the path [cow say], got %v", say.Path)
+	}
+	if !say.Executable || say.Description != "makes the cow talk" {
+		t.Errorf("unexpected sub-command spec %+v", say)
+	}
+	if len(say.Flags) != 1 || say.Flags[0].Kind != "int" || say.Flags[0].Default != "1" {
+		t.Errorf("unexpected sub-command flags %+v", say.Flags)
+	}
+}
+
+func TestApp_Execute_dumpSpec(t *testing.T) {
+	root := kli.NewCommand("cow", flag.ContinueOnError)
+	say := kli.NewCommand("say", flag.ContinueOnError)
+	say.String("what", "moo", "what the cow will say")
+	executed := false
+	say.Do(func(_ kli.Command, _ kli.KFlag) kli.Error {
+		executed = true
+		return nil
+	})
+	if err := root.SetChildren(say); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &kli.App{}
+	app.SetRoot(root)
+	app.EnableSpecDump()
+
+	// the spec is printed on the standard output
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- b
+	}()
+	code, kerr := app.Execute(kli.NewContext().SetArgs([]string{"say", "--kli-dump-spec", "-what", "hi"}))
+	os.Stdout = stdout
+	_ = w.Close()
+	dump := <-out
+
+	if code != kli.OK || kerr != nil {
+		t.Fatalf("expected code %d and no error, got %d and %v", kli.OK, code, kerr)
+	}
+	if executed {
+		t.Error("expected the command not to be executed when dumping the spec")
+	}
+
+	var spec kli.Spec
+	if err := json.Unmarshal(dump, &spec); err != nil {
+		t.Fatalf("expected the spec as JSON, got %v:\n%s", err, dump)
+	}
+	if spec.Version != kli.SpecVersion {
+		t.Errorf("expected the version %s, got %s", kli.SpecVersion, spec.Version)
+	}
+	if len(spec.Root.Children) != 1 || spec.Root.Children[0].Name != "say" {
+		t.Errorf("expected the child say, got %v", spec.Root.Children)
+	}
+	for _, f := range spec.Root.Flags {
+		if f.Name == kli.DumpSpecFlag {
+			t.Errorf("expected the flag %s to be hidden from the spec", kli.DumpSpecFlag)
+		}
+	}
+	if root.Lookup(kli.DumpSpecFlag) != nil {
+		t.Errorf("expected the flag %s not to be defined on the root", kli.DumpSpecFlag)
+	}
+
+	// without the flag the command is executed
+	code, kerr = app.Execute(kli.NewContext().SetArgs([]string{"say", "-what", "hi"}))
+	if code != kli.OK || kerr != nil || !executed {
+		t.Errorf("expected the command to be executed, got %d and %v", code, kerr)
+	}
+}
@@ -0,0 +1,88 @@
+package kli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// suggestionDistance is the maximum edit distance of a suggestion
+const suggestionDistance = 2
+
+// maxDistance returns the edit distance allowed for the word
+// a third of it's length, up to suggestionDistance, so short words
+// like ls and rm are not close to everything
+func maxDistance(word string) int {
+	return min(len([]rune(word))/3, suggestionDistance)
+}
+
+// suggest returns the candidates close to the word
+// a candidate is close when the Damerau-Levenshtein distance (optimal string alignment)
+// is at most maxDistance, or, when prefix is true, when it starts with the word
+func suggest(word string, candidates []string, prefix bool) []string {
+	var found []string
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		if seen[c] || c == "" {
+			continue
+		}
+		if distance(word, c) <= maxDistance(word) || (prefix && len(word) > 1 && strings.HasPrefix(c, word)) {
+			seen[c] = true
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// didYouMean formats the suggestions, an empty string if there is none
+func didYouMean(suggestions []string, prefix string) string {
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", prefix+s)
+	}
+	switch len(quoted) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf(", did you mean %s?", quoted[0])
+	}
+	return fmt.Sprintf(", did you mean one of %s?", strings.Join(quoted, ", "))
+}
+
+// distance returns the optimal string alignment distance of a and b
+// the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters to go from a to b
+func distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+cost)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,356 @@
+package kli
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ByteSize is a number of bytes, it's parsed from a human size
+// with a decimal, kB MB GB TB PB, or binary, KiB MiB GiB TiB PiB, unit.
+// the units are case sensitive, a lower case b is not a byte, KB is accepted for kB.
+// ex: 512, 512B, 10MiB, 1.5GB
+type ByteSize uint64
+
+const (
+	KB ByteSize = 1000
+	MB          = KB * 1000
+	GB          = MB * 1000
+	TB          = GB * 1000
+	PB          = TB * 1000
+
+	KiB ByteSize = 1 << 10
+	MiB          = KiB << 10
+	GiB          = MiB << 10
+	TiB          = GiB << 10
+	PiB          = TiB << 10
+)
+
+var byteUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"kB", KB}, {"KB", KB},
+}
+
+// ParseByteSize parses a human size, see ByteSize
+// the number is decimal, it must give a whole number of bytes
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	number, unit := strings.TrimSuffix(s, "B"), ByteSize(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.name) {
+			number, unit = s[:len(s)-len(u.name)], u.size
+			break
+		}
+	}
+
+	number = strings.TrimSpace(number)
+	size, ok := new(big.Rat), isDecimal(number)
+	if ok {
+		_, ok = size.SetString(number)
+	}
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes with an optional unit, ex: 10MiB", s)
+	}
+	size.Mul(size, new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(unit))))
+	if !size.IsInt() {
+		return 0, fmt.Errorf("invalid size %q, it's not a whole number of bytes", s)
+	}
+	if !size.Num().IsUint64() {
+		return 0, fmt.Errorf("invalid size %q, the maximum is %dB", s, uint64(math.MaxUint64))
+	}
+	return ByteSize(size.Num().Uint64()), nil
+}
+
+// isDecimal returns true if s is made of digits with an optional decimal point
+// ex: 10, 1.5 or .5
+func isDecimal(s string) bool {
+	digits, points := 0, 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.':
+			points++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && points <= 1
+}
+
+// String returns the size with the largest unit dividing it, ex: 10MiB
+func (b ByteSize) String() string {
+	for _, u := range byteUnits {
+		if b >= u.size && b%u.size == 0 {
+			return fmt.Sprintf("%d%s", b/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(b))
+}
+
+type byteSizeValue struct {
+	p *ByteSize
+}
+
+func (v byteSizeValue) Set(s string) error {
+	b, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*v.p = b
+	return nil
+}
+
+func (v byteSizeValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+// durationValue is the value of a Duration flag, unlike the one of the
+// flag package it reports the error of time.ParseDuration
+type durationValue time.Duration
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) String() string {
+	return (*time.Duration)(d).String()
+}
+
+func (d *durationValue) Get() interface{} {
+	return time.Duration(*d)
+}
+
+// timeValue is a time.Time parsed with the layout
+type timeValue struct {
+	p      *time.Time
+	layout string
+}
+
+func (v timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		return fmt.Errorf("expected the format %s", v.layout)
+	}
+	*v.p = t
+	return nil
+}
+
+func (v timeValue) String() string {
+	if v.p == nil || v.p.IsZero() {
+		return ""
+	}
+	return v.p.Format(v.layout)
+}
+
+type urlValue struct {
+	p **url.URL
+}
+
+func (v urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if !u.IsAbs() {
+		return fmt.Errorf("%q is not an absolute URL", s)
+	}
+	*v.p = u
+	return nil
+}
+
+func (v urlValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return (*v.p).String()
+}
+
+type ipValue struct {
+	p *net.IP
+}
+
+func (v ipValue) Set(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return fmt.Errorf("%q is not an IP address", s)
+	}
+	*v.p = ip
+	return nil
+}
+
+func (v ipValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return v.p.String()
+}
+
+type ipNetValue struct {
+	p **net.IPNet
+}
+
+func (v ipNetValue) Set(s string) error {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a CIDR network, ex: 10.0.0.0/8", s)
+	}
+	*v.p = n
+	return nil
+}
+
+func (v ipNetValue) String() string {
+	if v.p == nil || *v.p == nil {
+		return ""
+	}
+	return (*v.p).String()
+}
+
+// PathCheck is the validation of a Path flag, the checks can be combined
+// ex: PathDir | PathWritable
+type PathCheck int
+
+const (
+	// PathExists requires the path to exist
+	PathExists PathCheck = 1 << iota
+	// PathFile requires the path to be an existing file
+	PathFile
+	// PathDir requires the path to be an existing directory
+	PathDir
+	// PathWritable requires the path, or it's directory if it does not exist, to be writable
+	PathWritable
+)
+
+// pathString is the value of a Path flag, it's distinguished from a string flag
+type pathString string
+
+type pathValue struct {
+	p     *pathString
+	check PathCheck
+}
+
+func (v pathValue) Set(s string) error {
+	if err := v.check.validate(s); err != nil {
+		return err
+	}
+	*v.p = pathString(filepath.Clean(s))
+	return nil
+}
+
+func (v pathValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return string(*v.p)
+}
+
+// validate returns an error if the path fails the checks
+func (c PathCheck) validate(path string) error {
+	info, err := os.Stat(path)
+	exists := err == nil
+	if !exists && c&(PathExists|PathFile|PathDir) != 0 {
+		return fmt.Errorf("%s does not exist", path)
+	}
+	if c&PathFile != 0 && info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	if c&PathDir != 0 && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if c&PathWritable == 0 {
+		return nil
+	}
+
+	switch {
+	case !exists:
+		return writableDir(filepath.Dir(path))
+	case info.IsDir():
+		return writableDir(path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("%s is not writable", path)
+	}
+	return f.Close()
+}
+
+// Bytes sets a flag of type ByteSize, ex: -max-size 10MiB
+func (c *CMD) Bytes(name string, value ByteSize, usage string) {
+	p := new(ByteSize)
+	*p = value
+	c.FlagSet.Var(byteSizeValue{p}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = value })
+}
+
+// Time sets a flag of type time.Time in the RFC3339 format
+func (c *CMD) Time(name string, value time.Time, usage string) {
+	p := new(time.Time)
+	*p = value
+	c.FlagSet.Var(timeValue{p, time.RFC3339}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = value })
+}
+
+// Date sets a flag of type time.Time in the format 2006-01-02
+func (c *CMD) Date(name string, value time.Time, usage string) {
+	p := new(time.Time)
+	*p = value
+	c.FlagSet.Var(timeValue{p, "2006-01-02"}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = value })
+}
+
+// URL sets a flag of type *url.URL, the URL must be absolute
+func (c *CMD) URL(name string, value *url.URL, usage string) {
+	p := new(*url.URL)
+	*p = value
+	c.FlagSet.Var(urlValue{p}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = value })
+}
+
+// IP sets a flag of type net.IP
+func (c *CMD) IP(name string, value net.IP, usage string) {
+	p := new(net.IP)
+	*p = value
+	c.FlagSet.Var(ipValue{p}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = value })
+}
+
+// IPNet sets a flag of type *net.IPNet in the CIDR notation, ex: 10.0.0.0/8
+func (c *CMD) IPNet(name string, value *net.IPNet, usage string) {
+	p := new(*net.IPNet)
+	*p = value
+	c.FlagSet.Var(ipNetValue{p}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = value })
+}
+
+// Path sets a flag of type file path validated by the checks, 0 for none
+// the default value is not validated
+func (c *CMD) Path(name string, value string, check PathCheck, usage string) {
+	p := new(pathString)
+	*p = pathString(value)
+	c.FlagSet.Var(pathValue{p, check}, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *p = pathString(value) })
+}
@@ -0,0 +1,167 @@
+package kli_test
+
+import (
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want kli.ByteSize
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"10MiB", 10 * kli.MiB},
+		{"1.5GB", 1500 * kli.MB},
+		{"4kB", 4000},
+		{"4KB", 4000},
+		{"2 GiB", 2 * kli.GiB},
+		{"1.1MB", 1100 * kli.KB},
+		{".5KiB", 512},
+		{"18446744073709551615", 1<<64 - 1},
+	}
+	for _, tt := range tests {
+		got, err := kli.ParseByteSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: expected %d, got %d and %v", tt.in, tt.want, got, err)
+		}
+	}
+	for _, in := range []string{"ten MiB", "NaN", "inf", "-Inf", "1e30", "16384PiB", "18446744073709551616", "0.5B", "1.5", "10Mb", "4kb", "1..5MB", "-1kB"} {
+		if _, err := kli.ParseByteSize(in); err == nil {
+			t.Errorf("%s: expected an error for an invalid size", in)
+		}
+	}
+	if s := (10 * kli.MiB).String(); s != "10MiB" {
+		t.Errorf("expected 10MiB, got %s", s)
+	}
+}
+
+func TestCMD_Parse_types(t *testing.T) {
+	cmd := kli.NewCommand("fetch", flag.ContinueOnError)
+	cmd.Bytes("max-size", 10*kli.MiB, "the maximum size")
+	cmd.Time("since", time.Time{}, "fetch since")
+	cmd.Date("day", time.Time{}, "the day")
+	cmd.URL("endpoint", nil, "the server")
+	cmd.IP("bind", nil, "the bind address")
+	cmd.IPNet("allow", nil, "the allowed network")
+
+	if def := cmd.Lookup("max-size").DefValue; def != "10MiB" {
+		t.Errorf("expected the default 10MiB, got %s", def)
+	}
+
+	err := cmd.Parse([]string{
+		"-max-size", "1GB",
+		"-since", "2024-03-01T10:00:00Z",
+		"-day", "2024-03-02",
+		"-endpoint", "https://example.com/api",
+		"-bind", "127.0.0.1",
+		"-allow", "10.0.0.0/8",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size, ok := cmd.BytesFlag("max-size"); !ok || size != kli.GB {
+		t.Errorf("expected 1GB, got %s", size)
+	}
+	if since, ok := cmd.TimeFlag("since"); !ok || !since.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the timestamp, got %s", since)
+	}
+	if day, ok := cmd.TimeFlag("day"); !ok || day.Day() != 2 {
+		t.Errorf("expected the date, got %s", day)
+	}
+	if u, ok := cmd.URLFlag("endpoint"); !ok || u.Host != "example.com" {
+		t.Errorf("expected the URL, got %v", u)
+	}
+	if ip, ok := cmd.IPFlag("bind"); !ok || ip.String() != "127.0.0.1" {
+		t.Errorf("expected the IP, got %v", ip)
+	}
+	if n, ok := cmd.IPNetFlag("allow"); !ok || n.String() != "10.0.0.0/8" {
+		t.Errorf("expected the network, got %v", n)
+	}
+	if _, ok := cmd.URLFlag("bind"); ok {
+		t.Error("expected ok to be false for a flag of another type")
+	}
+}
+
+func TestCMD_Parse_typesInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"max-size", []string{"-max-size", "10XB"}},
+		{"since", []string{"-since", "yesterday"}},
+		{"endpoint", []string{"-endpoint", "example.com"}},
+		{"bind", []string{"-bind", "localhost"}},
+		{"allow", []string{"-allow", "10.0.0.0"}},
+	}
+	for _, tt := range tests {
+		cmd := kli.NewCommand("fetch", flag.ContinueOnError)
+		cmd.Bytes("max-size", 0, "the maximum size")
+		cmd.Time("since", time.Time{}, "fetch since")
+		cmd.URL("endpoint", nil, "the server")
+		cmd.IP("bind", nil, "the bind address")
+		cmd.IPNet("allow", nil, "the allowed network")
+		cmd.SetOutput(ioutil.Discard)
+
+		err := cmd.Parse(tt.args)
+		if err == nil || !strings.Contains(err.Error(), "-"+tt.name) {
+			t.Errorf("expected an error naming the flag -%s, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestCMD_Parse_path(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cow.txt")
+	if err := ioutil.WriteFile(file, []byte("moo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		check kli.PathCheck
+		value string
+		valid bool
+	}{
+		{"no check", 0, filepath.Join(dir, "missing"), true},
+		{"exists", kli.PathExists, file, true},
+		{"missing", kli.PathExists, filepath.Join(dir, "missing"), false},
+		{"file", kli.PathFile, file, true},
+		{"file is a directory", kli.PathFile, dir, false},
+		{"directory", kli.PathDir | kli.PathWritable, dir, true},
+		{"directory is a file", kli.PathDir, file, false},
+		{"writable new file", kli.PathWritable, filepath.Join(dir, "new.txt"), true},
+		{"writable file", kli.PathWritable, file, true},
+		{"missing directory", kli.PathWritable, filepath.Join(dir, "missing", "new.txt"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := kli.NewCommand("cat", flag.ContinueOnError)
+			cmd.Path("file", "", tt.check, "the file")
+			cmd.SetOutput(ioutil.Discard)
+
+			err := cmd.Parse([]string{"-file", tt.value})
+			if tt.valid && err != nil {
+				t.Errorf("expected %s to be valid, got %v", tt.value, err)
+			}
+			if !tt.valid && (err == nil || !strings.Contains(err.Error(), "-file")) {
+				t.Errorf("expected an error naming the flag -file, got %v", err)
+			}
+			if path, ok := cmd.PathFlag("file"); tt.valid && (!ok || path != tt.value) {
+				t.Errorf("expected the path %s, got %s", tt.value, path)
+			}
+		})
+	}
+}
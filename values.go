@@ -0,0 +1,169 @@
+package kli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the slice and map flags are repeatable, -tag a -tag b, and take
+// comma separated values, -tags a,b. The first value replaces the default
+
+// sliceValue is a flag.Value appending the comma separated values to a slice
+type sliceValue struct {
+	parse   func(s string) error
+	reset   func()
+	str     func() string
+	changed bool
+}
+
+func (s *sliceValue) Set(value string) error {
+	if !s.changed {
+		s.reset()
+		s.changed = true
+	}
+	for _, v := range strings.Split(value, ",") {
+		if err := s.parse(strings.TrimSpace(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sliceValue) String() string {
+	if s.str == nil {
+		return "[]"
+	}
+	return s.str()
+}
+
+func newStringSlice(value []string, p *[]string) *sliceValue {
+	*p = append([]string{}, value...)
+	return &sliceValue{
+		parse: func(s string) error {
+			*p = append(*p, s)
+			return nil
+		},
+		reset: func() { *p = []string{} },
+		str:   func() string { return "[" + strings.Join(*p, ",") + "]" },
+	}
+}
+
+func newIntSlice(value []int, p *[]int) *sliceValue {
+	*p = append([]int{}, value...)
+	return &sliceValue{
+		parse: func(s string) error {
+			i, err := strconv.Atoi(s)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, i)
+			return nil
+		},
+		reset: func() { *p = []int{} },
+		str: func() string {
+			values := make([]string, len(*p))
+			for i, v := range *p {
+				values[i] = strconv.Itoa(v)
+			}
+			return "[" + strings.Join(values, ",") + "]"
+		},
+	}
+}
+
+func newDurationSlice(value []time.Duration, p *[]time.Duration) *sliceValue {
+	*p = append([]time.Duration{}, value...)
+	return &sliceValue{
+		parse: func(s string) error {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, d)
+			return nil
+		},
+		reset: func() { *p = []time.Duration{} },
+		str: func() string {
+			values := make([]string, len(*p))
+			for i, v := range *p {
+				values[i] = v.String()
+			}
+			return "[" + strings.Join(values, ",") + "]"
+		},
+	}
+}
+
+// stringMap is a flag.Value setting the comma separated key=value pairs
+type stringMap struct {
+	value   *map[string]string
+	changed bool
+}
+
+func newStringMap(value map[string]string, p *map[string]string) *stringMap {
+	*p = make(map[string]string)
+	for k, v := range value {
+		(*p)[k] = v
+	}
+	return &stringMap{value: p}
+}
+
+func (m *stringMap) Set(value string) error {
+	if !m.changed {
+		*m.value = make(map[string]string)
+		m.changed = true
+	}
+	for _, pair := range strings.Split(value, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%q is not a key=value pair", pair)
+		}
+		(*m.value)[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return nil
+}
+
+func (m *stringMap) String() string {
+	if m.value == nil {
+		return "[]"
+	}
+	var pairs []string
+	for k, v := range *m.value {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+func (c *CMD) StringSlice(name string, value []string, usage string) {
+	p := new([]string)
+	v := newStringSlice(value, p)
+	c.FlagSet.Var(v, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *v = *newStringSlice(value, p) })
+}
+
+func (c *CMD) IntSlice(name string, value []int, usage string) {
+	p := new([]int)
+	v := newIntSlice(value, p)
+	c.FlagSet.Var(v, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *v = *newIntSlice(value, p) })
+}
+
+func (c *CMD) DurationSlice(name string, value []time.Duration, usage string) {
+	p := new([]time.Duration)
+	v := newDurationSlice(value, p)
+	c.FlagSet.Var(v, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *v = *newDurationSlice(value, p) })
+}
+
+func (c *CMD) StringMap(name string, value map[string]string, usage string) {
+	p := new(map[string]string)
+	v := newStringMap(value, p)
+	c.FlagSet.Var(v, name, usage)
+	c.KFlag.SetFlag(name, p)
+	c.setDefault(name, func() { *v = *newStringMap(value, p) })
+}
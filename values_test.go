@@ -0,0 +1,61 @@
+package kli_test
+
+import (
+	"flag"
+	"github.com/SamuelTissot/kli"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCMD_Parse_sliceAndMap(t *testing.T) {
+	cmd := kli.NewCommand("run", flag.ContinueOnError)
+	cmd.StringSlice("tag", []string{"latest"}, "the image tags")
+	cmd.IntSlice("port", []int{80}, "the exposed ports")
+	cmd.DurationSlice("retry", nil, "the retry delays")
+	cmd.StringMap("label", map[string]string{"app": "web"}, "the labels")
+
+	err := cmd.Parse([]string{"-tag", "a", "-tag", "b,c", "-retry", "1s,2m", "-label", "env=prod", "-label", "team=ops,tier=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tags, ok := cmd.StringSliceFlag("tag"); !ok || !reflect.DeepEqual(tags, []string{"a", "b", "c"}) {
+		t.Errorf("expected the tags to replace the default, got %v", tags)
+	}
+	if ports, ok := cmd.IntSliceFlag("port"); !ok || !reflect.DeepEqual(ports, []int{80}) {
+		t.Errorf("expected the default ports, got %v", ports)
+	}
+	if retries, ok := cmd.DurationSliceFlag("retry"); !ok || !reflect.DeepEqual(retries, []time.Duration{time.Second, 2 * time.Minute}) {
+		t.Errorf("expected the retry delays, got %v", retries)
+	}
+	want := map[string]string{"env": "prod", "team": "ops", "tier": "1"}
+	if labels, ok := cmd.StringMapFlag("label"); !ok || !reflect.DeepEqual(labels, want) {
+		t.Errorf("expected the labels %v, got %v", want, labels)
+	}
+
+	if _, ok := cmd.IntSliceFlag("tag"); ok {
+		t.Error("expected ok to be false for a flag of another type")
+	}
+	if kind := cmd.Store()["label"]; kind != reflect.Map {
+		t.Errorf("expected the kind map, got %s", kind)
+	}
+	if def := cmd.Lookup("label").DefValue; def != "[app=web]" {
+		t.Errorf("expected the default [app=web], got %s", def)
+	}
+}
+
+func TestCMD_Parse_sliceInvalid(t *testing.T) {
+	cmd := kli.NewCommand("run", flag.ContinueOnError)
+	cmd.IntSlice("port", nil, "the exposed ports")
+	cmd.StringMap("label", nil, "the labels")
+	cmd.SetOutput(ioutil.Discard)
+
+	if err := cmd.Parse([]string{"-port", "80,http"}); err == nil {
+		t.Error("expected an error for an invalid int")
+	}
+	if err := cmd.Parse([]string{"-label", "env"}); err == nil {
+		t.Error("expected an error for a value that is not a key=value pair")
+	}
+}
@@ -0,0 +1,80 @@
+package kli_test
+
+import (
+	"flag"
+	"fmt"
+	"github.com/SamuelTissot/kli"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type level int
+
+func (l *level) String() string {
+	return [...]string{"debug", "info", "error"}[*l]
+}
+
+func (l *level) Set(s string) error {
+	for i, name := range []string{"debug", "info", "error"} {
+		if s == name {
+			*l = level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %s", s)
+}
+
+func (l *level) Get() interface{} {
+	return *l
+}
+
+type semver struct {
+	major, minor, patch int
+}
+
+func (v *semver) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+func (v *semver) Set(s string) error {
+	_, err := fmt.Sscanf(strings.TrimPrefix(s, "v"), "%d.%d.%d", &v.major, &v.minor, &v.patch)
+	return err
+}
+
+func TestCMD_Var(t *testing.T) {
+	lvl := level(1)
+	version := &semver{}
+	cmd := kli.NewCommand("release", flag.ContinueOnError)
+	cmd.Var(&lvl, "level", "the log level")
+	cmd.Var(version, "version", "the released version")
+
+	if err := cmd.Parse([]string{"-level", "error", "-version", "v1.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	v, ok := cmd.VarFlag("level")
+	if !ok {
+		t.Fatal("expected the level flag.Value")
+	}
+	if got := v.(flag.Getter).Get(); got != level(2) {
+		t.Errorf("expected the level error, got %v", got)
+	}
+	if v, _ := cmd.VarFlag("version"); v.(*semver).minor != 2 {
+		t.Errorf("expected the version 1.2.3, got %s", v)
+	}
+	if _, ok := cmd.StringFlag("level"); ok {
+		t.Error("expected ok to be false for a custom flag.Value")
+	}
+	if _, ok := cmd.VarFlag("missing"); ok {
+		t.Error("expected ok to be false for a missing flag")
+	}
+
+	store := cmd.Store()
+	if store["level"] != reflect.Int {
+		t.Errorf("expected the kind of the flag.Getter value, got %s", store["level"])
+	}
+	if store["version"] != reflect.Struct {
+		t.Errorf("expected the kind of the flag.Value type, got %s", store["version"])
+	}
+}
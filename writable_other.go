@@ -0,0 +1,22 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package kli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// writableDir returns an error if a file can not be created in the directory
+// there is no access(2) on this platform: a temporary file is created in the
+// directory, then removed
+func writableDir(dir string) error {
+	f, err := ioutil.TempFile(dir, ".kli")
+	if err != nil {
+		return fmt.Errorf("%s is not writable", dir)
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
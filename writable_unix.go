@@ -0,0 +1,21 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package kli
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// wOK is the write permission mode of access(2)
+const wOK = 0x2
+
+// writableDir returns an error if a file can not be created in the directory
+// the permission is checked with access(2), nothing is written
+func writableDir(dir string) error {
+	if err := syscall.Access(dir, wOK); err != nil {
+		return fmt.Errorf("%s is not writable", dir)
+	}
+	return nil
+}